@@ -9,7 +9,10 @@ import (
 
 	"github.com/angelcodes95/contindex/internal/classifier"
 	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/frontmatter"
+	"github.com/angelcodes95/contindex/internal/manifest"
 	"github.com/angelcodes95/contindex/internal/template"
+	"github.com/angelcodes95/contindex/internal/tokens"
 	"github.com/angelcodes95/contindex/internal/validation"
 	"github.com/spf13/cobra"
 )
@@ -29,24 +32,40 @@ This enables selective chapter loading instead of processing everything.`,
 	RunE: runConvert,
 }
 
+// Preamble placement modes for text above the first section heading
+const (
+	preambleChapter = "chapter"
+	preambleInline  = "inline"
+)
+
 var (
 	sourceFile   string
-	templateType string
-	backupDir    string
-	contextDir   string
-	projectName  string
+	preambleMode string
+	vocabPacks   string
+	maxTokens    int
+	maxSource    string // Where maxTokens came from, for error messages
+	minTokens    int
+	nested       bool
 	noBackup     bool
 	force        bool
+	strict       bool
 )
 
 func init() {
 	convertCmd.Flags().StringVar(&sourceFile, "source", "CLAUDE.md", "Source monolithic context file")
-	convertCmd.Flags().StringVar(&templateType, "template", "claude", "Template type (claude, cursor, copilot, gemini, generic)")
-	convertCmd.Flags().StringVar(&backupDir, "backup-dir", "backup", "Backup directory for original file")
-	convertCmd.Flags().StringVar(&contextDir, "context-dir", "context", "Context directory name for chapter files")
-	convertCmd.Flags().StringVar(&projectName, "project", "Project", "Project name for index generation")
+	convertCmd.Flags().String("template", "", "Templates to write index files for: one or a comma-separated list of names from 'contindex template list', or all (default from "+config.FileName+", else "+config.DefaultTemplate+")")
+	convertCmd.Flags().String("backup-dir", "", "Backup directory for original file (default from "+config.FileName+", else "+config.DefaultBackupDir+")")
+	convertCmd.Flags().String("context-dir", "", "Context directory name for chapter files (default from "+config.FileName+", else "+config.DefaultContextDir+")")
+	convertCmd.Flags().String("project", "", "Project name for index generation (default from "+config.FileName+", else the directory name)")
+	convertCmd.Flags().StringVar(&preambleMode, "preamble", preambleChapter, "Where to put text above the first heading (chapter, inline)")
+	convertCmd.Flags().StringVar(&vocabPacks, "vocab", classifier.DefaultPackName, "Comma-separated vocabulary packs for chapter naming (backend, frontend, mobile, data-ml, infra, security, all)")
+	convertCmd.Flags().String("tokenizer", "", "Token counter: heuristic, cl100k_base or o200k_base (default from "+config.FileName+", else "+classifier.DefaultTokenizer+")")
+	convertCmd.Flags().IntVar(&maxTokens, "max-chapter-tokens", 0, "Split chapters larger than this many tokens at sub-headings or paragraphs (0 for no limit)")
+	convertCmd.Flags().IntVar(&minTokens, "min-chapter-tokens", 0, "Merge chapters smaller than this many tokens into a parent or neighbor (0 drops tiny sections)")
+	convertCmd.Flags().BoolVar(&nested, "nested", false, "Put top-level sections with subsections in their own subdirectory of the context dir")
 	convertCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup of original file")
 	convertCmd.Flags().BoolVar(&force, "force", false, "Overwrite existing context directory if it contains files")
+	convertCmd.Flags().BoolVar(&strict, "strict", false, "Abort if any source content would not be carried into a chapter or the index")
 	convertCmd.Flags().BoolP("dry-run", "d", false, "Preview changes without writing files")
 	rootCmd.AddCommand(convertCmd)
 }
@@ -54,75 +73,133 @@ func init() {
 func runConvert(cmd *cobra.Command, args []string) error {
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-	// If project name is still default, use directory name
-	if projectName == "Project" {
-		if wd, err := os.Getwd(); err == nil {
-			projectName = filepath.Base(wd)
+	projectConfig, err := loadProjectConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	// The template suggests a chapter budget unless the flag sets one
+	maxSource = "--max-chapter-tokens"
+	if !cmd.Flags().Changed("max-chapter-tokens") {
+		templateConfig, err := config.LookupTemplate(projectConfig.Template, projectConfig.ProjectRoot)
+		if err != nil {
+			return err
 		}
+		maxTokens = templateConfig.TokenLimits.Chapter
+		maxSource = fmt.Sprintf("token_limits.chapter of the %s template", projectConfig.Template)
+	}
+
+	if err := validateConvertInputs(projectConfig); err != nil {
+		return err
 	}
 
-	if err := validateConvertInputs(); err != nil {
+	vocabulary, err := loadVocabulary(getProjectPath(cmd))
+	if err != nil {
 		return err
 	}
 
-	printConversionStatus(dryRun)
+	tokenizer, err := classifier.NewTokenizer(projectConfig.Tokenizer)
+	if err != nil {
+		return fmt.Errorf("invalid --tokenizer: %w", err)
+	}
+
+	printConversionStatus(projectConfig, dryRun)
+	logVerbose(cmd, "Vocabulary packs: %s", strings.Join(vocabulary.PackNames(), ", "))
+	logVerbose(cmd, "Tokenizer: %s", tokenizer.Name())
+
+	contextFiles, coverage, decisions, err := analyzeAndGenerateFiles(vocabulary, tokenizer)
+	if err != nil {
+		return err
+	}
+
+	printCoverageReport(coverage)
+	if strict && !coverage.Lossless() {
+		return fmt.Errorf("conversion would lose %d of %d source lines (--strict enabled)",
+			coverage.TotalLines-coverage.CoveredLines, coverage.TotalLines)
+	}
+
+	contextFiles, preamble := separatePreamble(contextFiles)
+	if len(contextFiles) == 0 {
+		return fmt.Errorf("no content sections found in source file")
+	}
 
 	if !dryRun && !noBackup {
-		if err := createBackup(); err != nil {
+		if err := createBackup(projectConfig); err != nil {
 			return fmt.Errorf("failed to create backup: %w", err)
 		}
 	}
 
-	contextFiles, err := analyzeAndGenerateFiles()
+	sourceTokens, err := countSourceTokens(tokenizer)
 	if err != nil {
 		return err
 	}
 
 	if dryRun {
-		return previewConversion(contextFiles)
+		return previewConversion(projectConfig, contextFiles, preamble, decisions, tokenizer, sourceTokens)
+	}
+
+	chapterFiles, err := executeConversion(projectConfig, contextFiles, preamble, tokenizer)
+	if err != nil {
+		return err
 	}
 
-	if err := executeConversion(contextFiles); err != nil {
+	if err := recordConversionManifest(projectConfig, contextFiles, chapterFiles, tokenizer); err != nil {
 		return err
 	}
 
-	printConversionSuccess(contextFiles)
+	// Record the settings so update and watch use the same layout
+	if err := projectConfig.Save(); err != nil {
+		return err
+	}
+
+	printConversionSuccess(projectConfig, contextFiles, chapterFiles, tokenizer, sourceTokens)
 	return nil
 }
 
-func validateConvertInputs() error {
+func validateConvertInputs(projectConfig *config.ProjectConfig) error {
 	if err := validation.ValidateMarkdownFile(sourceFile); err != nil {
 		return fmt.Errorf("invalid source file: %w", err)
 	}
 
-	if err := validation.ValidateTemplateName(templateType); err != nil {
-		return fmt.Errorf("invalid template name: %w", err)
-	}
-
-	if err := config.ValidateTemplate(templateType); err != nil {
-		return fmt.Errorf("unsupported template type: %w", err)
+	for _, templateName := range projectConfig.Targets {
+		if err := validation.ValidateTemplateName(templateName); err != nil {
+			return fmt.Errorf("invalid template name: %w", err)
+		}
 	}
 
 	// Only validate backup directory if backups are enabled
 	if !noBackup {
-		if err := validation.ValidateDirectoryPath(backupDir); err != nil {
+		if err := validation.ValidateDirectoryPath(projectConfig.BackupDir); err != nil {
 			return fmt.Errorf("invalid backup directory: %w", err)
 		}
 	}
 
-	if err := validation.ValidateDirectoryPath(contextDir); err != nil {
+	if err := validation.ValidateDirectoryPath(projectConfig.ContextDir); err != nil {
 		return fmt.Errorf("invalid context directory: %w", err)
 	}
 
+	if maxTokens < 0 || minTokens < 0 {
+		return fmt.Errorf("chapter token limits cannot be negative")
+	}
+	if maxTokens > 0 && minTokens >= maxTokens {
+		return fmt.Errorf("--min-chapter-tokens (%d) must be smaller than %s (%d)", minTokens, maxSource, maxTokens)
+	}
+
+	if preambleMode != preambleChapter && preambleMode != preambleInline {
+		return fmt.Errorf("invalid preamble mode '%s': must be '%s' or '%s'", preambleMode, preambleChapter, preambleInline)
+	}
+
 	// Check for context directory conflicts
-	if err := checkDirectoryConflicts(); err != nil {
+	if err := checkDirectoryConflicts(projectConfig); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func checkDirectoryConflicts() error {
+func checkDirectoryConflicts(projectConfig *config.ProjectConfig) error {
+	contextDir := projectConfig.ContextDir
+
 	// Check if context directory already exists and has files
 	if stat, err := os.Stat(contextDir); err == nil && stat.IsDir() {
 		files, err := os.ReadDir(contextDir)
@@ -141,7 +218,7 @@ func checkDirectoryConflicts() error {
 
 	// Check if backup directory conflicts (when backups enabled)
 	if !noBackup {
-		if contextDir == backupDir {
+		if filepath.Clean(contextDir) == filepath.Clean(projectConfig.BackupDir) {
 			return fmt.Errorf("context directory and backup directory cannot be the same ('%s')", contextDir)
 		}
 	}
@@ -149,38 +226,144 @@ func checkDirectoryConflicts() error {
 	return nil
 }
 
-func printConversionStatus(dryRun bool) {
+func printConversionStatus(projectConfig *config.ProjectConfig, dryRun bool) {
 	if dryRun {
 		fmt.Printf("DRY RUN: Analyzing %s for file-based structure using %s template...\n",
-			sourceFile, templateType)
+			sourceFile, strings.Join(projectConfig.Targets, ", "))
 	} else {
 		fmt.Printf("Converting %s to file-based contindex structure using %s template...\n",
-			sourceFile, templateType)
+			sourceFile, strings.Join(projectConfig.Targets, ", "))
 	}
 }
 
-func analyzeAndGenerateFiles() ([]*classifier.ContextFile, error) {
+// loadVocabulary compiles the vocabulary packs selected with --vocab
+func loadVocabulary(projectPath string) (*classifier.Vocabulary, error) {
+	registry, err := classifier.LoadPackRegistry(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vocabulary packs: %w", err)
+	}
+
+	vocabulary, err := registry.Vocabulary(strings.Split(vocabPacks, ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --vocab: %w", err)
+	}
+	return vocabulary, nil
+}
+
+func analyzeAndGenerateFiles(vocabulary *classifier.Vocabulary, tokenizer classifier.Tokenizer) ([]*classifier.ContextFile, *classifier.CoverageReport, []classifier.SizingDecision, error) {
 	analyzer := classifier.NewFileAnalyzer(sourceFile)
+	analyzer.Vocabulary = vocabulary
+	analyzer.Tokenizer = tokenizer
+	analyzer.MaxChapterTokens = maxTokens
+	analyzer.MinChapterTokens = minTokens
+	analyzer.Nested = nested
 	contextFiles, err := analyzer.AnalyzeAndGenerate(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze and generate files: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to analyze and generate files: %w", err)
 	}
 
 	if len(contextFiles) == 0 {
-		return nil, fmt.Errorf("no content sections found in source file")
+		return nil, nil, nil, fmt.Errorf("no content sections found in source file")
+	}
+
+	return contextFiles, analyzer.Coverage(contextFiles), analyzer.SizingDecisions(), nil
+}
+
+// countSourceTokens counts the tokens an AI tool loads when it reads the monolithic source file
+func countSourceTokens(tokenizer classifier.Tokenizer) (int, error) {
+	content, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read source file: %w", err)
+	}
+	return tokenizer.Count(string(content)), nil
+}
+
+// printTokenReduction compares loading the whole source file with loading a single average chapter
+func printTokenReduction(sourceTokens, averageTokens int) {
+	if sourceTokens == 0 {
+		return
+	}
+	reduction := 100 * float64(sourceTokens-averageTokens) / float64(sourceTokens)
+	fmt.Printf("Source file: %d tokens; loading one average chapter instead saves %.0f%%\n", sourceTokens, reduction)
+}
+
+// describeTokenLimits summarizes the chapter token budget for reports
+func describeTokenLimits() string {
+	var limits []string
+	if minTokens > 0 {
+		limits = append(limits, fmt.Sprintf("min %d tokens", minTokens))
+	}
+	if maxTokens > 0 {
+		limits = append(limits, fmt.Sprintf("max %d tokens", maxTokens))
+	}
+	return strings.Join(limits, ", ")
+}
+
+// printCoverageReport shows how much of the source is carried into the output and what would be lost
+func printCoverageReport(coverage *classifier.CoverageReport) {
+	fmt.Printf("\nCoverage: %d/%d source lines (%.1f%%) mapped to chapters or the index\n",
+		coverage.CoveredLines, coverage.TotalLines, coverage.Percent())
+
+	if coverage.Lossless() {
+		return
+	}
+
+	fmt.Printf("Uncovered content (would not appear in any chapter):\n")
+	for _, lineRange := range coverage.Uncovered {
+		location := fmt.Sprintf("line %d", lineRange.Start)
+		if lineRange.End > lineRange.Start {
+			location = fmt.Sprintf("lines %d-%d", lineRange.Start, lineRange.End)
+		}
+		fmt.Printf("   %s: %s\n", location, tokens.TruncateRunes(coverage.FirstLine(lineRange), 60))
+	}
+	fmt.Printf("Use --strict to abort conversion when content would be lost\n")
+}
+
+// separatePreamble removes the preamble from the chapter list when it is to be inlined into the index
+func separatePreamble(contextFiles []*classifier.ContextFile) ([]*classifier.ContextFile, *classifier.ContextFile) {
+	if preambleMode != preambleInline {
+		return contextFiles, nil
 	}
 
-	return contextFiles, nil
+	var chapters []*classifier.ContextFile
+	var preamble *classifier.ContextFile
+	for _, file := range contextFiles {
+		if file.Preamble {
+			preamble = file
+			continue
+		}
+		chapters = append(chapters, file)
+	}
+	return chapters, preamble
 }
 
-func previewConversion(contextFiles []*classifier.ContextFile) error {
-	fmt.Printf("\nPREVIEW: Would create %d context files:\n\n", len(contextFiles))
+func previewConversion(projectConfig *config.ProjectConfig, contextFiles []*classifier.ContextFile, preamble *classifier.ContextFile, decisions []classifier.SizingDecision, tokenizer classifier.Tokenizer, sourceTokens int) error {
+	if len(decisions) > 0 {
+		fmt.Printf("\nChapter sizing (%s):\n", describeTokenLimits())
+		for _, decision := range decisions {
+			fmt.Printf("   %s\n", decision)
+		}
+	}
+
+	fmt.Printf("\nPREVIEW: Would create %d context files (vocabulary: %s):\n\n", len(contextFiles), vocabPacks)
 
 	totalTokens := 0
+	renamed := 0
 	for i, file := range contextFiles {
-		fmt.Printf("%d. %s\n", i+1, file.FileName)
+		fmt.Printf("%d. %s\n", i+1, file.Path())
+		if file.Preamble {
+			fmt.Printf("   Preamble: lines %d-%d above the first heading\n", file.StartLine, file.EndLine)
+		}
+		if file.Part > 0 {
+			fmt.Printf("   Part %d of %q, lines %d-%d\n", file.Part, file.Title, file.StartLine, file.EndLine)
+		}
+		if file.OriginalFileName != "" {
+			fmt.Printf("   Renamed: %s was already taken by another chapter\n", file.OriginalFileName)
+			renamed++
+		}
 		fmt.Printf("   Summary: %s\n", file.Summary)
-		fmt.Printf("   Size: %d words, ~%d tokens\n", file.WordCount, file.TokenCount)
+		fmt.Printf("   Size: %d words, %s\n", file.WordCount, formatTokens(file.TokenCount, tokenizer))
+		fmt.Printf("   Naming confidence: %.0f%%\n", file.Confidence*100)
 		if len(file.KeyTerms) > 0 {
 			fmt.Printf("   Key terms: %s\n", strings.Join(file.KeyTerms, ", "))
 		}
@@ -188,62 +371,188 @@ func previewConversion(contextFiles []*classifier.ContextFile) error {
 		totalTokens += file.TokenCount
 	}
 
-	fmt.Printf("Total estimated tokens: %d\n", totalTokens)
+	if renamed > 0 {
+		fmt.Printf("Name collisions: %d chapter names were changed to keep every file unique\n\n", renamed)
+	}
+
+	if preamble != nil {
+		fmt.Printf("Preamble: lines %d-%d (%d words) would be inlined into %s below its title\n",
+			preamble.StartLine, preamble.EndLine, preamble.WordCount, strings.Join(targetIndexFiles(projectConfig), ", "))
+		if preamble.DocumentTitle {
+			fmt.Printf("   Including the document title %q\n", preamble.Title)
+		}
+		fmt.Printf("\n")
+	}
+
+	fmt.Printf("Total tokens (%s): %d\n", tokenizer.Name(), totalTokens)
 	fmt.Printf("Average tokens per file: %d\n", totalTokens/len(contextFiles))
+	printTokenReduction(sourceTokens, totalTokens/len(contextFiles))
 
 	return nil
 }
 
-func executeConversion(contextFiles []*classifier.ContextFile) error {
-	if err := os.MkdirAll(contextDir, 0755); err != nil {
-		return fmt.Errorf("failed to create context directory: %w", err)
+// executeConversion writes the chapters and every target index. It returns
+// the chapter files written by templates such as cursor-rules.
+func executeConversion(projectConfig *config.ProjectConfig, contextFiles []*classifier.ContextFile, preamble *classifier.ContextFile, tokenizer classifier.Tokenizer) ([]string, error) {
+	if err := os.MkdirAll(projectConfig.ContextDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create context directory: %w", err)
 	}
 
-	if err := writeContextFiles(contextFiles, contextDir); err != nil {
-		return fmt.Errorf("failed to write context files: %w", err)
+	if err := writeContextFiles(contextFiles, projectConfig.ContextDir, tokenizer.Name()); err != nil {
+		return nil, fmt.Errorf("failed to write context files: %w", err)
 	}
 
-	if err := generateIndexFile(contextFiles); err != nil {
-		return fmt.Errorf("failed to generate index file: %w", err)
+	// Every target index is written from the same chapters
+	var chapterFiles []string
+	for _, target := range projectConfig.TargetConfigs() {
+		written, err := generateIndexFile(target, contextFiles, preamble)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate index file %s: %w", target.MainFile, err)
+		}
+		chapterFiles = append(chapterFiles, written...)
 	}
 
-	return nil
+	return chapterFiles, nil
 }
 
-func writeContextFiles(contextFiles []*classifier.ContextFile, contextDir string) error {
+// recordConversionManifest writes the manifest so the next update can tell
+// which chapters changed since conversion
+func recordConversionManifest(projectConfig *config.ProjectConfig, contextFiles []*classifier.ContextFile, chapterFiles []string, tokenizer classifier.Tokenizer) error {
+	previous, err := manifest.Load(projectConfig.ProjectRoot)
+	if err != nil {
+		return err
+	}
+	current, err := buildManifest(projectConfig.ProjectRoot, projectConfig.ContextDir, contextFiles, tokenizer)
+	if err != nil {
+		return err
+	}
+	indexFiles := append(targetIndexFiles(projectConfig), chapterFiles...)
+	return saveManifest(projectConfig.ProjectRoot, previous, current, indexFiles, nil)
+}
+
+// targetIndexFiles returns the index file of every target template
+func targetIndexFiles(projectConfig *config.ProjectConfig) []string {
+	var indexFiles []string
+	for _, target := range projectConfig.TargetConfigs() {
+		indexFiles = append(indexFiles, target.MainFile)
+	}
+	return indexFiles
+}
+
+// writeContextFiles writes each chapter with a front-matter block recording
+// where it came from, followed by its original heading and body
+func writeContextFiles(contextFiles []*classifier.ContextFile, contextDir string, tokenizerName string) error {
 	for _, file := range contextFiles {
-		filePath := filepath.Join(contextDir, file.FileName)
+		fileDir := filepath.Join(contextDir, filepath.FromSlash(file.Group))
+		if err := os.MkdirAll(fileDir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", fileDir, err)
+		}
+
+		title := file.Title
+		if title == "" {
+			title = strings.TrimSuffix(file.FileName, ".md")
+		}
+		metadata := &frontmatter.Chapter{
+			Title:       title,
+			HeadingPath: file.HeadingPath,
+			Source:      sourceFile,
+			StartLine:   file.StartLine,
+			EndLine:     file.EndLine,
+			Part:        file.Part,
+			Summary:     file.Summary,
+			KeyTerms:    file.KeyTerms,
+			Tokens:      file.TokenCount,
+			Tokenizer:   tokenizerName,
+		}
 
-		content := fmt.Sprintf("# %s\n\n%s\n",
-			strings.TrimSuffix(file.FileName, ".md"), file.Content)
+		filePath := filepath.Join(fileDir, file.FileName)
+		content := fmt.Sprintf("%s\n# %s\n\n%s\n", metadata.Format(), title, file.Content)
 
 		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", file.FileName, err)
+			return fmt.Errorf("failed to write file %s: %w", file.Path(), err)
 		}
 	}
 
 	return nil
 }
 
-func generateIndexFile(contextFiles []*classifier.ContextFile) error {
-	// Use template system to create index file
-	projectConfig := config.DefaultConfig(".")
-	projectConfig.ContextDir = contextDir // Use configurable context directory
-	if err := projectConfig.UpdateForTemplate(templateType); err != nil {
-		return fmt.Errorf("failed to configure template: %w", err)
+// generateIndexFile writes the index of one target and, for templates that
+// define chapter files, the file of every chapter next to it. It returns the
+// chapter files written.
+func generateIndexFile(projectConfig *config.ProjectConfig, contextFiles []*classifier.ContextFile, preamble *classifier.ContextFile) ([]string, error) {
+	chapters := templateChapters(contextFiles)
+	templateConfig, err := config.LookupTemplate(projectConfig.Template, projectConfig.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// Render the chapter files first so a name collision leaves no index behind
+	var chapterFiles []*indexFile
+	if templateConfig.ChapterFile != "" {
+		chapterFiles, err = renderChapterFiles(projectConfig, templateConfig, chapters)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Create template manager and generate index
 	templateManager := template.New()
-	if err := templateManager.ApplyTemplate(projectConfig); err != nil {
-		return fmt.Errorf("failed to apply template: %w", err)
+	if err := templateManager.ApplyTemplate(projectConfig, chapters); err != nil {
+		return nil, fmt.Errorf("failed to apply template: %w", err)
+	}
+
+	if preamble != nil {
+		if err := insertPreamble(projectConfig.MainFile, preamble); err != nil {
+			return nil, err
+		}
+	}
+
+	var written []string
+	for _, file := range chapterFiles {
+		if err := writeIndexFile(file.path, file.rendered); err != nil {
+			return nil, fmt.Errorf("failed to write chapter file %s: %w", file.path, err)
+		}
+		written = append(written, file.path)
+	}
+	return written, nil
+}
+
+// insertPreamble places the source preamble directly below the index title
+func insertPreamble(mainFile string, preamble *classifier.ContextFile) error {
+	content, err := os.ReadFile(mainFile)
+	if err != nil {
+		return fmt.Errorf("failed to read index file: %w", err)
+	}
+	return os.WriteFile(mainFile, []byte(inlinePreamble(string(content), preamble)), 0644)
+}
+
+// inlinePreamble returns the index with the preamble inserted below its
+// title. The document title of the source is kept as the first line of the
+// preamble so that no source line is lost.
+func inlinePreamble(index string, preamble *classifier.ContextFile) string {
+	lines := strings.SplitAfter(index, "\n")
+	insertAt := 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, "# ") {
+			insertAt = i + 1
+			break
+		}
+	}
+
+	text := strings.TrimSpace(preamble.Content)
+	if preamble.DocumentTitle {
+		text = strings.TrimSpace("# " + preamble.Title + "\n\n" + text)
 	}
 
-	// Update template with AI-generated chapter information
-	return UpdateTemplateWithChapters(projectConfig.MainFile, contextFiles, contextDir)
+	var updated strings.Builder
+	updated.WriteString(strings.Join(lines[:insertAt], ""))
+	updated.WriteString("\n" + text + "\n")
+	updated.WriteString(strings.Join(lines[insertAt:], ""))
+	return updated.String()
 }
 
-func createBackup() error {
+func createBackup(projectConfig *config.ProjectConfig) error {
+	backupDir := projectConfig.BackupDir
 	if err := validation.ValidateDirectoryWritable(backupDir); err != nil {
 		return fmt.Errorf("backup directory validation failed: %w", err)
 	}
@@ -272,7 +581,7 @@ func createBackup() error {
 	return nil
 }
 
-func printConversionSuccess(contextFiles []*classifier.ContextFile) {
+func printConversionSuccess(projectConfig *config.ProjectConfig, contextFiles []*classifier.ContextFile, chapterFiles []string, tokenizer classifier.Tokenizer, sourceTokens int) {
 	totalWords := 0
 	totalTokens := 0
 
@@ -282,64 +591,53 @@ func printConversionSuccess(contextFiles []*classifier.ContextFile) {
 	}
 
 	fmt.Printf("\nSuccessfully converted %s to index-chapter architecture\n", sourceFile)
-	fmt.Printf("Created %d chapter files in %s/ directory\n", len(contextFiles), contextDir)
-	fmt.Printf("Total content: %d words, ~%d tokens\n", totalWords, totalTokens)
+	fmt.Printf("Created %d chapter files in %s/ directory\n", len(contextFiles), projectConfig.ContextDir)
+	fmt.Printf("Total content: %d words, %s\n", totalWords, formatTokens(totalTokens, tokenizer))
 	fmt.Printf("Average per chapter: %d tokens\n", totalTokens/len(contextFiles))
-	fmt.Printf("Index file: %s\n", getIndexFileName(templateType))
+	printTokenReduction(sourceTokens, totalTokens/len(contextFiles))
+	fmt.Printf("Index files: %s\n", strings.Join(targetIndexFiles(projectConfig), ", "))
+	if len(chapterFiles) > 0 {
+		fmt.Printf("Chapter files: %d written next to the index\n", len(chapterFiles))
+	}
+	if preambleMode == preambleInline {
+		fmt.Printf("Preamble: inlined into the index file\n")
+	}
 	if !noBackup {
-		fmt.Printf("Backup saved in: %s/\n", backupDir)
+		fmt.Printf("Backup saved in: %s/\n", projectConfig.BackupDir)
 	} else {
 		fmt.Printf("Backup: skipped (--no-backup)\n")
 	}
 
 	fmt.Printf("\nNext steps:\n")
-	fmt.Printf("1. Review generated chapter files in %s/ directory\n", contextDir)
+	fmt.Printf("1. Review generated chapter files in %s/ directory\n", projectConfig.ContextDir)
 	fmt.Printf("2. Check the index file - it references all chapters\n")
 	fmt.Printf("3. AI tools can now load specific chapters instead of everything\n")
 }
 
-func getIndexFileName(templateType string) string {
-	switch templateType {
-	case "claude":
-		return "CLAUDE.md"
-	case "cursor":
-		return "AGENTS.md"
-	case "copilot":
-		return "copilot-instructions.md"
-	case "gemini":
-		return "GEMINI.md"
-	default:
-		return "template.md"
+// formatTokens marks heuristic counts as estimates
+func formatTokens(tokens int, tokenizer classifier.Tokenizer) string {
+	if tokenizer.Name() == classifier.HeuristicTokenizer {
+		return fmt.Sprintf("~%d tokens", tokens)
 	}
+	return fmt.Sprintf("%d tokens (%s)", tokens, tokenizer.Name())
 }
 
-// UpdateTemplateWithChapters updates the template file with AI-generated chapter names
-func UpdateTemplateWithChapters(mainFile string, contextFiles []*classifier.ContextFile, contextDirName string) error {
-	// Read the current template file
-	content, err := os.ReadFile(mainFile)
-	if err != nil {
-		return fmt.Errorf("failed to read template file: %w", err)
-	}
-
-	// Generate simple chapter list - AI already created semantic names
-	var chapterList strings.Builder
-	for i, file := range contextFiles {
-		// Use the AI-generated descriptive filename as the TOC entry
-		descriptiveName := strings.TrimSuffix(file.FileName, ".md")
-		chapterList.WriteString(fmt.Sprintf("%d. **%s** - `%s/%s`\n", i+1, descriptiveName, contextDirName, file.FileName))
-	}
-
-	// Replace placeholder with actual chapter list
-	placeholders := []string{
-		"(Chapter files will be listed here when you run `contindex update` or `contindex convert`)",
-		"(Context files will be listed here when you run `contindex update` or `contindex convert`)",
-	}
-
-	updatedContent := string(content)
-	for _, placeholder := range placeholders {
-		updatedContent = strings.ReplaceAll(updatedContent, placeholder, strings.TrimSpace(chapterList.String()))
-	}
-
-	// Write updated content
-	return os.WriteFile(mainFile, []byte(updatedContent), 0644)
+// templateChapters converts analyzed chapter files to template data
+func templateChapters(contextFiles []*classifier.ContextFile) []template.Chapter {
+	chapters := make([]template.Chapter, 0, len(contextFiles))
+	for _, file := range contextFiles {
+		chapters = append(chapters, template.Chapter{
+			// Use the AI-generated descriptive filename as the TOC entry
+			Name:     strings.TrimSuffix(file.FileName, ".md"),
+			Title:    file.Title,
+			Path:     file.Path(),
+			Group:    file.Group,
+			Summary:  file.Summary,
+			KeyTerms: file.KeyTerms,
+			Tokens:   file.TokenCount,
+			Globs:    file.Globs,
+			Content:  file.Content,
+		})
+	}
+	return chapters
 }
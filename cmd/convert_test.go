@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angelcodes95/contindex/internal/classifier"
+	"github.com/angelcodes95/contindex/internal/config"
+)
+
+// captureOutput returns what fn prints to standard output
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// setPreambleMode sets the --preamble flag for one test
+func setPreambleMode(t *testing.T, mode string) {
+	t.Helper()
+	previous := preambleMode
+	preambleMode = mode
+	t.Cleanup(func() { preambleMode = previous })
+}
+
+func TestSeparatePreamble(t *testing.T) {
+	preamble := &classifier.ContextFile{FileName: classifier.PreambleFileName, Preamble: true}
+	chapter := &classifier.ContextFile{FileName: "setup.md"}
+
+	tests := []struct {
+		mode         string
+		wantChapters int
+		wantPreamble *classifier.ContextFile
+	}{
+		{preambleChapter, 2, nil},
+		{preambleInline, 1, preamble},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			setPreambleMode(t, tt.mode)
+			chapters, got := separatePreamble([]*classifier.ContextFile{preamble, chapter})
+			if len(chapters) != tt.wantChapters || got != tt.wantPreamble {
+				t.Errorf("separatePreamble() = %d chapters, preamble %v; want %d, %v", len(chapters), got, tt.wantChapters, tt.wantPreamble)
+			}
+		})
+	}
+}
+
+func TestInlinePreamble(t *testing.T) {
+	index := "# demo Context Index\n\nIntro.\n"
+
+	tests := []struct {
+		name     string
+		preamble *classifier.ContextFile
+		want     string
+	}{
+		{
+			name:     "document title is kept",
+			preamble: &classifier.ContextFile{Title: "Acme Engineering Rules", Content: "Always run tests.", DocumentTitle: true, Preamble: true},
+			want:     "# demo Context Index\n\n# Acme Engineering Rules\n\nAlways run tests.\n\nIntro.\n",
+		},
+		{
+			name:     "no document title",
+			preamble: &classifier.ContextFile{Title: classifier.DefaultPreambleTitle, Content: "Always run tests.", Preamble: true},
+			want:     "# demo Context Index\n\nAlways run tests.\n\nIntro.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inlinePreamble(index, tt.preamble); got != tt.want {
+				t.Errorf("inlinePreamble() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewConversionPreamble(t *testing.T) {
+	root := t.TempDir()
+	projectConfig, err := config.Load(root, config.Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenizer, err := classifier.NewTokenizer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	preamble := &classifier.ContextFile{FileName: classifier.PreambleFileName, Title: "Acme Engineering Rules", Content: "Always run tests.",
+		StartLine: 1, EndLine: 3, WordCount: 3, Preamble: true, DocumentTitle: true}
+	chapter := &classifier.ContextFile{FileName: "setup.md", Title: "Setup", StartLine: 4, EndLine: 6, TokenCount: 10}
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{preambleChapter, []string{"1. " + classifier.PreambleFileName, "Preamble: lines 1-3 above the first heading", "2. setup.md"}},
+		{preambleInline, []string{"1. setup.md", "would be inlined into", `Including the document title "Acme Engineering Rules"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			setPreambleMode(t, tt.mode)
+			chapters, inlined := separatePreamble([]*classifier.ContextFile{preamble, chapter})
+			out := captureOutput(t, func() {
+				if err := previewConversion(projectConfig, chapters, inlined, nil, tokenizer, 100); err != nil {
+					t.Error(err)
+				}
+			})
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("preview does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteConversionWritesChapterFiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	projectConfig, err := config.Load(root, config.Settings{Template: config.TemplateList{"claude", "cursor-rules"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenizer, err := classifier.NewTokenizer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextFiles := []*classifier.ContextFile{
+		{FileName: "setup.md", Title: "Setup", Summary: "Install the tools.", Content: "Run make."},
+		{FileName: "deploy.md", Group: "guides", Title: "Deploy", Content: "Push a tag."},
+	}
+
+	chapterFiles, err := executeConversion(projectConfig, contextFiles, nil, tokenizer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chapterFiles) != 2 {
+		t.Errorf("executeConversion() wrote %d chapter files, want 2: %v", len(chapterFiles), chapterFiles)
+	}
+
+	rules := filepath.Join(root, ".cursor", "rules")
+	for _, want := range []string{
+		filepath.Join(root, "CLAUDE.md"),
+		filepath.Join(rules, "contindex.mdc"),
+		filepath.Join(rules, "setup.mdc"),
+		filepath.Join(rules, "guides-deploy.mdc"),
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("convert did not write %s: %v", want, err)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(rules, "setup.mdc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "description: \"Install the tools.\"") || !strings.Contains(string(content), "Run make.") {
+		t.Errorf("setup.mdc does not hold the chapter:\n%s", content)
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies hooks written by contindex so they can be replaced safely
+const hookMarker = "# Installed by 'contindex hooks install'"
+
+// hooksCmd represents the hooks command
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks that keep the index in sync",
+	Long: `Hooks manages git hooks for contindex projects.
+
+Available subcommands:
+  install  - Add a pre-commit hook that runs 'contindex update --check'`,
+}
+
+// hooksInstallCmd installs the pre-commit hook
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a pre-commit hook that checks the index for drift",
+	Long: `Install writes a git pre-commit hook that runs 'contindex update --check'.
+
+The commit is rejected when chapter files changed without the index being
+regenerated, and the hook prints the drift as a unified diff. Run
+'contindex update' and stage the index to fix it.
+
+An existing pre-commit hook is only replaced when it was installed by
+contindex or when --force is given.`,
+	RunE: runHooksInstall,
+}
+
+var forceHook bool
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd)
+
+	hooksInstallCmd.Flags().String("template", "",
+		"Templates of the index files to check, as accepted by update (default from "+config.FileName+")")
+	hooksInstallCmd.Flags().BoolVar(&forceHook, "force", false,
+		"Replace an existing pre-commit hook that was not installed by contindex")
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	projectPath := getProjectPath(cmd)
+
+	if err := validation.ValidateDirectoryPath(projectPath); err != nil {
+		return fmt.Errorf("invalid project path: %w", err)
+	}
+	hookTemplate := changedFlag(cmd, "template")
+	if hookTemplate != "" {
+		if _, err := config.ExpandTemplates(config.ParseTemplateList(hookTemplate), projectPath); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+
+	hooksDir, repoRoot, err := findGitHooks(projectPath)
+	if err != nil {
+		return err
+	}
+	logVerbose(cmd, "Git hooks directory: %s", hooksDir)
+
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	if existing, err := os.ReadFile(hookPath); err == nil {
+		if !strings.Contains(string(existing), hookMarker) && !forceHook {
+			return fmt.Errorf("a pre-commit hook already exists at %s\nAdd 'contindex update --check' to it yourself, or use --force to replace it", hookPath)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read existing hook: %w", err)
+	}
+
+	// The hook runs from the repository root, so point it at the project
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
+	}
+	projectRef, err := relativeSlashPath(repoRoot, absProject)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(preCommitHook(hookTemplate, projectRef)), 0755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	fmt.Printf("✓ Installed pre-commit hook: %s\n", hookPath)
+	fmt.Printf("Commits will fail while the index is out of date with its chapters.\n")
+	return nil
+}
+
+// preCommitHook returns the hook script. Without a template the check uses
+// the project config file. Contributors without contindex installed get a
+// warning instead of a blocked commit; CI still runs the check.
+func preCommitHook(templateName, projectRef string) string {
+	command := "contindex update --check"
+	if templateName != "" {
+		command += " --template=" + shellQuote(templateName)
+	}
+	if projectRef != "." {
+		command += " --path " + shellQuote(projectRef)
+	}
+
+	return fmt.Sprintf(`#!/bin/sh
+%s
+# Rejects commits whose index file is out of date with its chapter files.
+if ! command -v contindex >/dev/null 2>&1; then
+    echo "contindex not found in PATH; skipping index check" >&2
+    exit 0
+fi
+exec %s
+`, hookMarker, command)
+}
+
+// shellQuote quotes a value as a single word for sh
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// findGitHooks returns the hooks directory and the root of the repository
+// containing projectPath, honoring core.hooksPath
+func findGitHooks(projectPath string) (string, string, error) {
+	output, err := exec.Command("git", "-C", projectPath, "rev-parse", "--show-toplevel", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("%s is not inside a git repository (or git is not installed): %w", projectPath, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return "", "", fmt.Errorf("unexpected output from git rev-parse: %q", output)
+	}
+	repoRoot, hooksDir := lines[0], lines[1]
+
+	// A relative hooks path is relative to the directory git ran in
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(projectPath, hooksDir)
+	}
+	return hooksDir, repoRoot, nil
+}
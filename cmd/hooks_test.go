@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPreCommitHookQuoting(t *testing.T) {
+	tests := []struct {
+		name         string
+		templateName string
+		projectRef   string
+		want         string // Arguments sh passes to contindex, one per line
+	}{
+		{
+			name:         "plain values",
+			templateName: "claude,cursor",
+			projectRef:   "docs",
+			want:         "update\n--check\n--template=claude,cursor\n--path\ndocs",
+		},
+		{
+			name:         "spaces and metacharacters stay one word",
+			templateName: "claude, cursor; touch pwned",
+			projectRef:   "it's $HOME",
+			want:         "update\n--check\n--template=claude, cursor; touch pwned\n--path\nit's $HOME",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := preCommitHook(tt.templateName, tt.projectRef)
+			command := strings.TrimPrefix(hook[strings.LastIndex(hook, "exec "):], "exec ")
+
+			// Run the command line with contindex replaced by printf to see its arguments
+			script := strings.Replace(strings.TrimSpace(command), "contindex", `printf '%s\n'`, 1)
+			out, err := exec.Command("sh", "-c", script).Output()
+			if err != nil {
+				t.Fatalf("sh -c %q: %v", script, err)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("hook arguments = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
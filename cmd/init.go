@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/angelcodes95/contindex/internal/config"
 	"github.com/angelcodes95/contindex/internal/template"
@@ -24,11 +25,10 @@ This command creates:
 
 For existing monolithic files, use 'contindex convert' instead.
 
-Templates available:
-  claude   - Optimized for Claude Code (creates CLAUDE.md)
-  cursor   - Optimized for Cursor IDE (creates AGENTS.md)  
-  copilot  - Optimized for GitHub Copilot (creates .github/copilot-instructions.md)
-  generic  - Universal template (creates context-index.md)`,
+The chosen settings are saved in .contindex.json, which every command reads.
+
+Run 'contindex template list' to see the available templates and the index
+file each one creates. Without --template or a saved setting, ` + config.DefaultTemplate + ` is used.`,
 	RunE: runInit,
 }
 
@@ -36,8 +36,10 @@ func init() {
 	rootCmd.AddCommand(initCmd)
 
 	// Template selection flag
-	initCmd.Flags().StringP("template", "t", "generic",
-		"Template type (generic, claude, cursor, copilot)")
+	initCmd.Flags().StringP("template", "t", "",
+		"Templates to create index files for: one or a comma-separated list of names from 'contindex template list', or all (default from "+config.FileName+", else "+config.DefaultTemplate+")")
+	initCmd.Flags().String("context-dir", "",
+		"Context directory for chapter files (default from "+config.FileName+", else "+config.DefaultContextDir+")")
 
 	// Force flag for overwriting existing structure
 	initCmd.Flags().BoolP("force", "f", false,
@@ -46,23 +48,19 @@ func init() {
 
 func runInit(cmd *cobra.Command, args []string) error {
 	projectPath := getProjectPath(cmd)
-	templateName, _ := cmd.Flags().GetString("template")
 	force, _ := cmd.Flags().GetBool("force")
 
 	logVerbose(cmd, "Initializing contindex in: %s", projectPath)
-	logVerbose(cmd, "Using template: %s", templateName)
 
 	// Comprehensive input validation
 	if err := validation.ValidateDirectoryPath(projectPath); err != nil {
 		return fmt.Errorf("invalid project path: %w", err)
 	}
 
-	if err := validation.ValidateTemplateName(templateName); err != nil {
-		return fmt.Errorf("invalid template name: %w", err)
-	}
-
-	if err := config.ValidateTemplate(templateName); err != nil {
-		return fmt.Errorf("unsupported template: %w", err)
+	for _, name := range config.ParseTemplateList(changedFlag(cmd, "template")) {
+		if err := validation.ValidateTemplateName(name); err != nil {
+			return fmt.Errorf("invalid template name: %w", err)
+		}
 	}
 
 	// Validate project directory is writable
@@ -70,13 +68,12 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("project directory not writable: %w", err)
 	}
 
-	// Create project configuration
-	projectConfig := config.DefaultConfig(projectPath)
-	if err := projectConfig.UpdateForTemplate(templateName); err != nil {
-		return fmt.Errorf("failed to configure template: %v", err)
+	// Load project configuration
+	projectConfig, err := loadProjectConfig(cmd)
+	if err != nil {
+		return err
 	}
-
-	logVerbose(cmd, "Project config created: %+v", projectConfig)
+	logVerbose(cmd, "Using templates: %s", strings.Join(projectConfig.Targets, ", "))
 
 	// Check if structure already exists
 	if !force {
@@ -90,10 +87,17 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create directory structure: %v", err)
 	}
 
-	// Create main context file from template
+	// Create the main context file of every target from its template
 	templateManager := template.New()
-	if err := templateManager.ApplyTemplate(projectConfig); err != nil {
-		return fmt.Errorf("failed to create context file from template: %v", err)
+	for _, target := range projectConfig.TargetConfigs() {
+		if err := templateManager.ApplyTemplate(target, nil); err != nil {
+			return fmt.Errorf("failed to create context file from template: %v", err)
+		}
+	}
+
+	// Record the settings so later commands use them
+	if err := projectConfig.Save(); err != nil {
+		return err
 	}
 
 	// Success message with next steps
@@ -109,10 +113,12 @@ func checkExistingStructure(config *config.ProjectConfig) error {
 			config.ContextDir)
 	}
 
-	// Check if main context file exists
-	if _, err := os.Stat(config.MainFile); err == nil {
-		return fmt.Errorf("main context file already exists: %s\nUse --force to overwrite",
-			config.MainFile)
+	// Check if any main context file exists
+	for _, target := range config.TargetConfigs() {
+		if _, err := os.Stat(target.MainFile); err == nil {
+			return fmt.Errorf("main context file already exists: %s\nUse --force to overwrite",
+				target.MainFile)
+		}
 	}
 
 	return nil
@@ -131,9 +137,15 @@ func createDirectoryStructure(cmd *cobra.Command, projectConfig *config.ProjectC
 		logVerbose(cmd, "Warning: could not create .gitkeep in context directory: %v", err)
 	}
 
-	// Create subdirectory for main file if needed (e.g., .github for copilot)
-	templateConfig := config.TemplateConfigs[projectConfig.Template]
-	if templateConfig.SubDir != "" {
+	// Create subdirectory for main files if needed (e.g., .github for copilot)
+	for _, templateName := range projectConfig.Targets {
+		templateConfig, err := config.LookupTemplate(templateName, projectConfig.ProjectRoot)
+		if err != nil {
+			return err
+		}
+		if templateConfig.SubDir == "" {
+			continue
+		}
 		subDirPath := filepath.Join(projectConfig.ProjectRoot, templateConfig.SubDir)
 		logVerbose(cmd, "Creating subdirectory for template: %s", subDirPath)
 		if err := os.MkdirAll(subDirPath, 0755); err != nil {
@@ -158,13 +170,16 @@ func printInitSuccessMessage(projectConfig *config.ProjectConfig) {
 
 	fmt.Printf("Created:\n")
 	fmt.Printf("  %s/     # Directory for individual context files\n", projectConfig.ContextDir)
-	fmt.Printf("  %s     # Main context index file\n\n", projectConfig.MainFile)
+	for _, target := range projectConfig.TargetConfigs() {
+		fmt.Printf("  %s     # Main context index file\n", target.MainFile)
+	}
+	fmt.Printf("  %s     # Project settings read by every command\n\n", filepath.Join(projectConfig.ProjectRoot, config.FileName))
 
 	fmt.Printf("Next steps:\n")
 	fmt.Printf("1. Use 'contindex convert --source=YOUR_FILE.md' to convert existing monolithic files\n")
-	fmt.Printf("2. Or manually add descriptively-named .md files to the context/ directory\n")
+	fmt.Printf("2. Or manually add descriptively-named .md files to %s\n", projectConfig.ContextDir)
 	fmt.Printf("3. Start using your AI tool with selective file loading\n\n")
 
-	fmt.Printf("Template: %s\n", projectConfig.Template)
+	fmt.Printf("Template: %s\n", strings.Join(projectConfig.Targets, ", "))
 	fmt.Printf("AI tools can now load specific files instead of processing everything.\n")
 }
@@ -3,6 +3,9 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/angelcodes95/contindex/internal/classifier"
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/template"
 	"github.com/spf13/cobra"
 )
 
@@ -41,6 +44,9 @@ func Execute() error {
 }
 
 func init() {
+	// Index files record the version that wrote them
+	template.Version = Version
+
 	// Global flags
 	rootCmd.PersistentFlags().StringP("path", "p", ".", "Project directory path")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose output")
@@ -73,3 +79,50 @@ func logVerbose(cmd *cobra.Command, format string, args ...interface{}) {
 		fmt.Printf("[DEBUG] "+format+"\n", args...)
 	}
 }
+
+// addProjectFlags adds the flags that override the project configuration
+// for commands that work on an existing index
+func addProjectFlags(cmd *cobra.Command) {
+	cmd.Flags().String("template", "",
+		"Templates to write index files for: one or a comma-separated list of names from 'contindex template list', or all (default from "+config.FileName+", else "+config.DefaultTemplate+")")
+	addChapterFlags(cmd)
+}
+
+// addChapterFlags adds the flags that override where chapters are read from
+// and how they are counted
+func addChapterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("context-dir", "",
+		"Context directory for chapter files (default from "+config.FileName+", else "+config.DefaultContextDir+")")
+	cmd.Flags().String("tokenizer", "",
+		"Token counter: heuristic, cl100k_base or o200k_base (default from "+config.FileName+", else "+classifier.DefaultTokenizer+")")
+}
+
+// loadProjectConfig resolves the project configuration. Flags given on the
+// command line override CONTINDEX_* environment variables, which override
+// the project config file and the built-in defaults.
+func loadProjectConfig(cmd *cobra.Command) (*config.ProjectConfig, error) {
+	flags := config.Settings{
+		Template:    config.ParseTemplateList(changedFlag(cmd, "template")),
+		ContextDir:  changedFlag(cmd, "context-dir"),
+		BackupDir:   changedFlag(cmd, "backup-dir"),
+		ProjectName: changedFlag(cmd, "project"),
+		Tokenizer:   changedFlag(cmd, "tokenizer"),
+	}
+
+	projectConfig, err := config.Load(getProjectPath(cmd), flags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project configuration: %w", err)
+	}
+	logVerbose(cmd, "Project config: %+v", projectConfig)
+	return projectConfig, nil
+}
+
+// changedFlag returns the value of a string flag given on the command line,
+// or "" when the flag was not given or the command does not define it
+func changedFlag(cmd *cobra.Command, name string) string {
+	if !cmd.Flags().Changed(name) {
+		return ""
+	}
+	value, _ := cmd.Flags().GetString(name)
+	return value
+}
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
-	"text/template"
 
+	"github.com/angelcodes95/contindex/internal/classifier"
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/diff"
 	contindexTemplate "github.com/angelcodes95/contindex/internal/template"
 	"github.com/spf13/cobra"
 )
@@ -16,26 +19,29 @@ var templateCmd = &cobra.Command{
 	Long: `Template command provides operations for managing context file templates.
 
 Available subcommands:
-  list  - Show all available templates
-  show  - Display template details and content
-  info  - Get detailed information about a specific template
+  list      - Show all available templates
+  show      - Display template details and content
+  info      - Get detailed information about a specific template
+  export    - Copy a built-in template to disk for editing
+  validate  - Check that templates render and keep a chapter region
+  render    - Print the files a template writes for this project
 
 Templates determine how the main context file is structured and what
-reference syntax is used for different AI tools.`,
+reference syntax is used for different AI tools.
+
+Templates are searched in the project (.contindex/templates/<name>/template.md),
+then in the user config directory (contindex/templates/<name>/template.md),
+then among the built-in templates. A template on disk with the name of a
+built-in template replaces it.`,
 }
 
 // templateListCmd lists available templates
 var templateListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available templates",
-	Long: `List displays all built-in templates available for context organization.
-
-Each template is optimized for specific AI tools:
-- generic: Universal template that can be adapted to any AI tool
-- claude: Optimized for Claude Code with @context/ references
-- cursor: Designed for Cursor IDE with folder icons  
-- copilot: GitHub Copilot compatible with .github placement
-- gemini: Optimized for Google Gemini conversational context loading`,
+	Long: `List displays all templates available for context organization, including
+templates on disk, with the description and index file from each template's
+template.json.`,
 	RunE: runTemplateList,
 }
 
@@ -63,32 +69,109 @@ var templateInfoCmd = &cobra.Command{
 	RunE: runTemplateInfo,
 }
 
+// templateExportCmd copies a built-in template to disk
+var templateExportCmd = &cobra.Command{
+	Use:   "export <template-name>",
+	Short: "Copy a built-in template to disk for editing",
+	Long: `Export copies a built-in template into the project template directory
+(.contindex/templates/<name>/), where it replaces the built-in template for
+this project. Use --user to export to the user template directory so every
+project uses it, and --as to save it under a new name instead.
+
+Run 'contindex template validate' after editing.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateExport,
+}
+
+// templateValidateCmd checks templates
+var templateValidateCmd = &cobra.Command{
+	Use:   "validate [template-name...]",
+	Short: "Check that templates render and keep a chapter region",
+	Long: `Validate renders templates with sample chapters and checks that the chapter
+list sits in a managed region that update can refresh.
+
+Without arguments, every template available to the project is checked.`,
+	RunE: runTemplateValidate,
+}
+
+// templateRenderCmd renders a template with the project's chapters
+var templateRenderCmd = &cobra.Command{
+	Use:   "render <template-name>",
+	Short: "Print the files a template writes for this project",
+	Long: `Render scans the project's chapters as update does and prints the index
+file the template would produce, without writing anything. An existing index
+keeps its content outside the managed chapter region, exactly as update
+leaves it.
+
+For templates that write one file per chapter, such as cursor-rules, every
+chapter file follows the index, each under a "==> path <==" header.
+
+Use --diff to print the changes against the files on disk instead.
+Unlike 'template show', which uses made-up sample data, render uses the
+project's real chapters and configuration.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateRender,
+}
+
+var (
+	renderDiff bool
+
+	exportUser  bool
+	exportAs    string
+	exportForce bool
+)
+
 func init() {
 	rootCmd.AddCommand(templateCmd)
 	templateCmd.AddCommand(templateListCmd)
 	templateCmd.AddCommand(templateShowCmd)
 	templateCmd.AddCommand(templateInfoCmd)
+	templateCmd.AddCommand(templateExportCmd)
+	templateCmd.AddCommand(templateValidateCmd)
+	templateCmd.AddCommand(templateRenderCmd)
 
 	// Flags for template show
 	templateShowCmd.Flags().BoolP("raw", "r", false,
 		"Show raw template without processing")
+
+	// Flags for template render
+	addChapterFlags(templateRenderCmd)
+	templateRenderCmd.Flags().BoolVar(&renderDiff, "diff", false,
+		"Print a unified diff against the files on disk instead of the rendered files")
+
+	// Flags for template export
+	templateExportCmd.Flags().BoolVar(&exportUser, "user", false,
+		"Export to the user template directory instead of the project")
+	templateExportCmd.Flags().StringVar(&exportAs, "as", "",
+		"Name of the exported template (default: the built-in name, replacing it)")
+	templateExportCmd.Flags().BoolVar(&exportForce, "force", false,
+		"Overwrite an existing template on disk")
 }
 
 func runTemplateList(cmd *cobra.Command, args []string) error {
+	projectPath := getProjectPath(cmd)
+
 	templateManager := contindexTemplate.New()
-	templates := templateManager.ListTemplates()
+	templates, err := templateManager.ListTemplates(projectPath)
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("Available Templates\n\n")
 
 	for _, templateName := range templates {
-		info, err := templateManager.GetTemplateInfo(templateName)
+		info, err := templateManager.GetTemplateInfo(templateName, projectPath)
 		if err != nil {
 			logVerbose(cmd, "Warning: could not get info for template %s: %v", templateName, err)
 			fmt.Printf("   %s - (no description available)\n", templateName)
 			continue
 		}
 
-		fmt.Printf("   %s - %s\n", templateName, info.Description)
+		description := info.Description
+		if description == "" {
+			description = "(no description available)"
+		}
+		fmt.Printf("   %s - %s\n", templateName, description)
 
 		// Show main file info
 		mainFile := info.MainFile
@@ -96,6 +179,9 @@ func runTemplateList(cmd *cobra.Command, args []string) error {
 			mainFile = fmt.Sprintf("%s/%s", info.SubDir, info.MainFile)
 		}
 		fmt.Printf("     File: %s\n", mainFile)
+		if info.Dir != "" {
+			fmt.Printf("     From: %s\n", info.Dir)
+		}
 	}
 
 	fmt.Println()
@@ -109,8 +195,9 @@ func runTemplateShow(cmd *cobra.Command, args []string) error {
 	templateName := args[0]
 	raw, _ := cmd.Flags().GetBool("raw")
 
+	projectPath := getProjectPath(cmd)
 	templateManager := contindexTemplate.New()
-	info, err := templateManager.GetTemplateInfo(templateName)
+	info, err := templateManager.GetTemplateInfo(templateName, projectPath)
 	if err != nil {
 		return fmt.Errorf("template not found: %v", err)
 	}
@@ -123,7 +210,7 @@ func runTemplateShow(cmd *cobra.Command, args []string) error {
 		fmt.Println(info.Content)
 	} else {
 		fmt.Println("--- Template Preview ---")
-		preview, err := generateTemplatePreview(info)
+		preview, err := generateTemplatePreview(info, projectPath)
 		if err != nil {
 			return fmt.Errorf("failed to generate preview: %v", err)
 		}
@@ -139,7 +226,7 @@ func runTemplateInfo(cmd *cobra.Command, args []string) error {
 	templateName := args[0]
 
 	templateManager := contindexTemplate.New()
-	info, err := templateManager.GetTemplateInfo(templateName)
+	info, err := templateManager.GetTemplateInfo(templateName, getProjectPath(cmd))
 	if err != nil {
 		return fmt.Errorf("template not found: %v", err)
 	}
@@ -153,38 +240,31 @@ func runTemplateInfo(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Subdirectory: %s\n", info.SubDir)
 		fmt.Printf("Full path: %s/%s\n", info.SubDir, info.MainFile)
 	}
+	if info.ChapterFile != "" {
+		fmt.Printf("Chapter files: %s, one per chapter next to the main file\n", info.ChapterFile)
+	}
+	if info.Dir != "" {
+		fmt.Printf("Loaded from: %s\n", info.Dir)
+	} else {
+		fmt.Printf("Loaded from: built-in\n")
+	}
 
 	// Show compatible AI tools
-	fmt.Printf("\nCompatible AI Tools:\n")
-	switch templateName {
-	case "claude":
-		fmt.Printf("   - Claude Code (primary)\n")
-		fmt.Printf("   - Claude web interface\n")
-		fmt.Printf("   - Any tool that supports @context/ references\n")
-	case "cursor":
-		fmt.Printf("   - Cursor IDE (primary)\n")
-		fmt.Printf("   - VS Code with appropriate extensions\n")
-	case "copilot":
-		fmt.Printf("   - GitHub Copilot (primary)\n")
-		fmt.Printf("   - GitHub Copilot for VS Code\n")
-		fmt.Printf("   - GitHub Copilot CLI\n")
-	case "generic":
-		fmt.Printf("   - Any AI coding tool\n")
-		fmt.Printf("   - Universal compatibility\n")
+	if len(info.Tools) > 0 {
+		fmt.Printf("\nCompatible AI Tools:\n")
+		for _, tool := range info.Tools {
+			fmt.Printf("   - %s\n", tool)
+		}
 	}
 
 	// Show reference syntax
 	fmt.Printf("\nReference Syntax:\n")
-	switch templateName {
-	case "claude":
-		fmt.Printf("   Individual files are referenced directly\n")
-	case "cursor":
-		fmt.Printf("   Individual files are referenced directly\n")
-	case "copilot":
-		fmt.Printf("   Individual files are referenced directly\n")
-	case "generic":
-		fmt.Printf("   Individual files are referenced directly\n")
-	}
+	fmt.Printf("   Chapters are referenced as %s\n", info.Reference("context/authentication.md"))
+
+	// Show token limits
+	fmt.Printf("\nToken Limits:\n")
+	fmt.Printf("   Index file: %s\n", describeLimit(info.TokenLimits.Index))
+	fmt.Printf("   Chapters (convert --max-chapter-tokens default): %s\n", describeLimit(info.TokenLimits.Chapter))
 
 	// Show usage example
 	fmt.Printf("\nUsage:\n")
@@ -194,42 +274,169 @@ func runTemplateInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func generateTemplatePreview(info *contindexTemplate.Info) (string, error) {
-	// Create sample template data
-	sampleData := struct {
-		ProjectName      string
-		ProjectRoot      string
-		ContextDir       string
-		Categories       []struct{ Name, Description, Path string }
-		Template         string
-		GeneratedAt      string
-		ContindexVersion string
-		ReferenceSyntax  string
-	}{
-		ProjectName: "sample-project",
-		ProjectRoot: "/path/to/project",
-		ContextDir:  "/path/to/project/context",
-		Categories: []struct{ Name, Description, Path string }{
-			{"sample-file-1", "Example descriptively named file", "context/sample-file-1.md"},
-			{"sample-file-2", "Another example file with semantic naming", "context/sample-file-2.md"},
-			{"sample-file-3", "Third example showing file-based organization", "context/sample-file-3.md"},
-		},
-		Template:         info.Name,
-		GeneratedAt:      "2024-01-01 12:00:00",
-		ContindexVersion: "0.0.3",
-		ReferenceSyntax:  "@context/%s/",
-	}
-
-	// Parse and execute template
-	tmpl, err := template.New("preview").Parse(info.Content)
+func runTemplateExport(cmd *cobra.Command, args []string) error {
+	builtinName := args[0]
+	projectPath := getProjectPath(cmd)
+
+	name := builtinName
+	if exportAs != "" {
+		name = exportAs
+	}
+
+	dir := filepath.Join(projectPath, config.ProjectTemplateDir)
+	if exportUser {
+		dir = config.UserTemplateDir()
+		if dir == "" {
+			return fmt.Errorf("cannot determine the user config directory")
+		}
+	}
+
+	templateManager := contindexTemplate.New()
+	templateFile, err := templateManager.ExportTemplate(builtinName, name, dir, exportForce)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	fmt.Printf("✓ Exported template %s to %s\n", builtinName, templateFile)
+	if name == builtinName {
+		fmt.Printf("It replaces the built-in %s template", name)
+		if exportUser {
+			fmt.Printf(" for every project without its own copy.\n")
+		} else {
+			fmt.Printf(" in this project.\n")
+		}
+	} else {
+		fmt.Printf("Use it with: contindex init --template=%s\n", name)
 	}
+	fmt.Printf("Check your edits with: contindex template validate %s\n", name)
+	return nil
+}
+
+func runTemplateValidate(cmd *cobra.Command, args []string) error {
+	projectPath := getProjectPath(cmd)
 
-	var result strings.Builder
-	if err := tmpl.Execute(&result, sampleData); err != nil {
-		return "", err
+	templateManager := contindexTemplate.New()
+	templates := args
+	if len(templates) == 0 {
+		var err error
+		templates, err = templateManager.ListTemplates(projectPath)
+		if err != nil {
+			return err
+		}
 	}
 
-	return result.String(), nil
+	var invalid []string
+	for _, templateName := range templates {
+		if err := templateManager.ValidateTemplate(templateName, projectPath); err != nil {
+			fmt.Printf("✗ %s: %v\n", templateName, err)
+			invalid = append(invalid, templateName)
+			continue
+		}
+		fmt.Printf("✓ %s\n", templateName)
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	// Invalid templates are a result, not a usage mistake
+	cmd.SilenceUsage = true
+	return fmt.Errorf("invalid templates: %s", strings.Join(invalid, ", "))
+}
+
+func runTemplateRender(cmd *cobra.Command, args []string) error {
+	templateName := args[0]
+
+	projectConfig, err := loadProjectConfig(cmd)
+	if err != nil {
+		return err
+	}
+	target, err := projectConfig.ForTemplate(templateName)
+	if err != nil {
+		return err
+	}
+
+	tokenizer, err := classifier.NewTokenizer(projectConfig.Tokenizer)
+	if err != nil {
+		return fmt.Errorf("invalid tokenizer: %w", err)
+	}
+	chapterFiles, err := loadChapters(target, tokenizer)
+	if err != nil {
+		return err
+	}
+	logVerbose(cmd, "Found %d chapter files", len(chapterFiles))
+
+	files, err := renderTemplateFiles(target, templateChapters(chapterFiles))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if renderDiff {
+			printRenderDiff(file)
+			continue
+		}
+		// A lone index is printed as is so it can be redirected to a file
+		if len(files) > 1 {
+			fmt.Printf("==> %s <==\n", file.ref)
+		}
+		fmt.Print(file.rendered)
+	}
+	return nil
+}
+
+// renderTemplateFiles renders the index of a template followed by the chapter
+// files it writes, as update would
+func renderTemplateFiles(target *config.ProjectConfig, chapters []contindexTemplate.Chapter) ([]*indexFile, error) {
+	current, rendered, err := renderIndex(target, chapters)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := relativeSlashPath(target.ProjectRoot, target.MainFile)
+	if err != nil {
+		return nil, err
+	}
+	files := []*indexFile{{path: target.MainFile, ref: ref, current: current, rendered: rendered}}
+
+	// Templates such as cursor-rules also write one file per chapter
+	templateConfig, err := config.LookupTemplate(target.Template, target.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if templateConfig.ChapterFile == "" {
+		return files, nil
+	}
+	chapterFiles, err := renderChapterFiles(target, templateConfig, chapters)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, chapterFiles...), nil
+}
+
+// printRenderDiff prints the changes render would make to one file
+func printRenderDiff(file *indexFile) {
+	kind := "Index file"
+	if file.chapter != "" {
+		kind = "Chapter file"
+	}
+	if file.current == file.rendered {
+		fmt.Printf("✓ %s %s matches the rendered template\n", kind, file.ref)
+		return
+	}
+	fmt.Print(diff.Unified(file.ref, file.ref+" (rendered)", file.current, file.rendered, diff.DefaultContext))
+}
+
+func generateTemplatePreview(info *contindexTemplate.Info, projectPath string) (string, error) {
+	// Create sample template data
+	sampleData := contindexTemplate.SampleData(info.Name)
+	sampleData.ReferenceSyntax = info.ReferenceSyntax
+
+	return contindexTemplate.New().ExecuteTemplate(info.Name, projectPath, sampleData)
+}
+
+// describeLimit formats a token limit, where zero means none
+func describeLimit(limit int) string {
+	if limit == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d tokens", limit)
 }
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/template"
+)
+
+func TestRenderTemplateFiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	chapters := []template.Chapter{
+		{Name: "setup", Title: "Setup", Path: "setup.md", Summary: "Install the tools."},
+		{Name: "deploy", Title: "Deploy", Path: "guides/deploy.md", Group: "guides"},
+	}
+
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"claude", []string{"CLAUDE.md"}},
+		{"cursor-rules", []string{".cursor/rules/contindex.mdc", ".cursor/rules/setup.mdc", ".cursor/rules/guides-deploy.mdc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			projectConfig, err := config.Load(root, config.Settings{Template: config.TemplateList{tt.template}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			files, err := renderTemplateFiles(projectConfig, chapters)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var refs []string
+			for _, file := range files {
+				refs = append(refs, file.ref)
+				if file.rendered == "" {
+					t.Errorf("%s rendered empty", file.ref)
+				}
+			}
+			if strings.Join(refs, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("renderTemplateFiles() = %v, want %v", refs, tt.want)
+			}
+		})
+	}
+}
@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/angelcodes95/contindex/internal/classifier"
 	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/diff"
+	"github.com/angelcodes95/contindex/internal/frontmatter"
+	"github.com/angelcodes95/contindex/internal/manifest"
+	"github.com/angelcodes95/contindex/internal/markdown"
 	"github.com/angelcodes95/contindex/internal/template"
 	"github.com/angelcodes95/contindex/internal/validation"
 	"github.com/spf13/cobra"
@@ -26,156 +32,736 @@ This command:
 4. Maintains the lightweight table of contents
 
 Use this command when you've added, removed, or modified chapter files
-and need the index to reflect the current state.`,
+and need the index to reflect the current state.
+
+With --check, update writes nothing. It prints a unified diff of the changes
+it would make and exits non-zero when the index is out of date, which suits
+CI jobs and the pre-commit hook installed by 'contindex hooks install'.`,
 	RunE: runUpdate,
 }
 
 var (
-	updateTemplate string
-	forceUpdate    bool
+	forceUpdate  bool
+	migrateIndex bool
+	checkIndex   bool
 )
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
 
-	updateCmd.Flags().StringVar(&updateTemplate, "template", "claude",
-		"Template type for index file (claude, cursor, copilot, generic)")
+	addProjectFlags(updateCmd)
 	updateCmd.Flags().BoolVar(&forceUpdate, "force", false,
 		"Force update even if no changes detected")
+	updateCmd.Flags().BoolVar(&migrateIndex, "migrate", false,
+		"Add managed region markers to an index file written before they existed")
+	updateCmd.Flags().BoolVar(&checkIndex, "check", false,
+		"Write nothing; print the drift between the index file and its chapters and exit non-zero if they differ")
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
 	projectPath := getProjectPath(cmd)
 
 	logVerbose(cmd, "Updating index in: %s", projectPath)
-	logVerbose(cmd, "Using template: %s", updateTemplate)
 
 	// Validate inputs
 	if err := validation.ValidateDirectoryPath(projectPath); err != nil {
 		return fmt.Errorf("invalid project path: %w", err)
 	}
 
-	if err := config.ValidateTemplate(updateTemplate); err != nil {
-		return fmt.Errorf("invalid template: %w", err)
+	projectConfig, err := loadProjectConfig(cmd)
+	if err != nil {
+		return err
+	}
+	logVerbose(cmd, "Using templates: %s", strings.Join(projectConfig.Targets, ", "))
+
+	tokenizer, err := classifier.NewTokenizer(projectConfig.Tokenizer)
+	if err != nil {
+		return fmt.Errorf("invalid tokenizer: %w", err)
+	}
+
+	state, err := loadIndexState(projectConfig, tokenizer)
+	if err != nil {
+		return err
+	}
+
+	if len(state.chapters) == 0 {
+		fmt.Printf("No chapter files found in %s\n", projectConfig.ContextDir)
+		fmt.Printf("Add .md files to %s and run update again\n", projectConfig.ContextDir)
+		return nil
+	}
+	logVerbose(cmd, "Found %d chapter files", len(state.chapters))
+
+	if checkIndex {
+		return checkIndexDrift(cmd, state)
+	}
+
+	// Check if update is needed (unless forced or migrating)
+	if state.upToDate() && !forceUpdate && !migrateIndex {
+		fmt.Printf("Index files are up to date. Use --force to regenerate anyway.\n")
+		return nil
+	}
+	printChangeSummary(state.previous, state.changes)
+
+	written, err := state.write()
+	if err != nil {
+		return err
+	}
+
+	// Success message
+	printUpdateSuccess(state.indexFiles(), state.current.ContextDir, state.chapters, tokenizer)
+	printChapterFileSync(state, written)
+	warnOversizedIndexes(state.indexes, tokenizer)
+
+	return nil
+}
+
+// indexState is the chapters of a project and its index files as found on
+// disk, together with the index files update would write for them
+type indexState struct {
+	config   *config.ProjectConfig
+	chapters []*classifier.ContextFile
+	previous *manifest.Manifest
+	current  *manifest.Manifest
+	changes  manifest.Changes
+	indexes  []*indexFile
+	stale    []string // Chapter files of removed chapters, which update deletes
+	removed  []string // Stale chapter files deleted by write
+}
+
+// indexFile is the index of one target template, or a file a template such
+// as cursor-rules writes for one chapter
+type indexFile struct {
+	path     string // Path of the index file
+	ref      string // Path relative to the project root, as the manifest records it
+	current  string // Content on disk, empty when the file does not exist
+	rendered string // Content update would write
+	limit    int    // Token budget of the index from the template, zero for none
+	chapter  string // Chapter the file is written for, empty for an index
+}
+
+// loadIndexState scans and analyzes the chapters of a project once and
+// renders the index file of every target template in memory. Nothing is
+// written. A project without chapters returns a state with no chapters and
+// no rendered index.
+func loadIndexState(projectConfig *config.ProjectConfig, tokenizer classifier.Tokenizer) (*indexState, error) {
+	projectPath := projectConfig.ProjectRoot
+
+	// The manifest remembers the chapters and index of the last run
+	previous, err := manifest.Load(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	chapterFiles, err := loadChapters(projectConfig, tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &indexState{
+		config:   projectConfig,
+		chapters: chapterFiles,
+		previous: previous,
+	}
+	if len(chapterFiles) == 0 {
+		return state, nil
+	}
+
+	state.current, err = buildManifest(projectPath, projectConfig.ContextDir, chapterFiles, tokenizer)
+	if err != nil {
+		return nil, err
+	}
+	state.changes = manifest.Diff(previous, state.current)
+
+	chapters := templateChapters(chapterFiles)
+
+	for _, target := range projectConfig.TargetConfigs() {
+		ref, err := relativeSlashPath(projectPath, target.MainFile)
+		if err != nil {
+			return nil, err
+		}
+		current, rendered, err := renderIndex(target, chapters)
+		if err != nil {
+			return nil, err
+		}
+		templateConfig, err := config.LookupTemplate(target.Template, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		state.indexes = append(state.indexes, &indexFile{
+			path:     target.MainFile,
+			ref:      ref,
+			current:  current,
+			rendered: rendered,
+			limit:    templateConfig.TokenLimits.Index,
+		})
+
+		if templateConfig.ChapterFile == "" {
+			continue
+		}
+		chapterFiles, err := renderChapterFiles(target, templateConfig, chapters)
+		if err != nil {
+			return nil, err
+		}
+		state.indexes = append(state.indexes, chapterFiles...)
+		state.stale = append(state.stale, staleChapterFiles(previous, target, templateConfig, chapterFiles)...)
 	}
+	return state, nil
+}
 
+// renderChapterFiles renders the file a template writes next to its index
+// for every chapter. These files are generated in full; edits to them are
+// overwritten by the next update.
+func renderChapterFiles(target *config.ProjectConfig, templateConfig config.TemplateConfig, chapters []template.Chapter) ([]*indexFile, error) {
+	templateManager := template.New()
+	dir := filepath.Dir(target.MainFile)
+
+	// Chapter paths are flattened, so two chapters can map to one file
+	owners := make(map[string]string)
+	var files []*indexFile
+	for _, chapter := range chapters {
+		filePath := filepath.Join(dir, templateConfig.ChapterFileName(chapter.Path))
+		if filePath == target.MainFile {
+			return nil, fmt.Errorf("chapter %s would overwrite index file %s; rename the chapter", chapter.Path, target.MainFile)
+		}
+		if owner, ok := owners[filePath]; ok {
+			return nil, fmt.Errorf("chapters %s and %s both map to chapter file %s; rename one of them", owner, chapter.Path, filePath)
+		}
+		owners[filePath] = chapter.Path
+		ref, err := relativeSlashPath(target.ProjectRoot, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read chapter file %s: %w", filePath, err)
+		}
+		rendered, err := templateManager.RenderChapter(target, chapters, chapter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render chapter file %s: %w", filePath, err)
+		}
+
+		files = append(files, &indexFile{
+			path:     filePath,
+			ref:      ref,
+			current:  string(content),
+			rendered: rendered,
+			chapter:  chapter.Path,
+		})
+	}
+	return files, nil
+}
+
+// staleChapterFiles returns the chapter files the last update wrote for a
+// template whose chapters no longer exist. Only files still on disk are
+// returned.
+func staleChapterFiles(previous *manifest.Manifest, target *config.ProjectConfig, templateConfig config.TemplateConfig, current []*indexFile) []string {
+	if previous == nil {
+		return nil
+	}
+	written := make(map[string]bool)
+	for _, file := range current {
+		written[file.path] = true
+	}
+
+	dir := filepath.Dir(target.MainFile)
+	prefix, suffix, _ := strings.Cut(templateConfig.ChapterFile, "%s")
+	var stale []string
+	for _, index := range previous.Indexes {
+		filePath := filepath.Join(target.ProjectRoot, filepath.FromSlash(index.Path))
+		name := filepath.Base(filePath)
+		if filepath.Dir(filePath) != dir || filePath == target.MainFile || written[filePath] ||
+			!strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		if _, err := os.Stat(filePath); err == nil {
+			stale = append(stale, filePath)
+		}
+	}
+	return stale
+}
+
+// loadChapters scans the context directory of a project and analyzes its
+// chapters as update does
+func loadChapters(projectConfig *config.ProjectConfig, tokenizer classifier.Tokenizer) ([]*classifier.ContextFile, error) {
 	// Check if context directory exists
-	contextDir := filepath.Join(projectPath, "context")
+	contextDir := projectConfig.ContextDir
 	if _, err := os.Stat(contextDir); os.IsNotExist(err) {
-		return fmt.Errorf("context directory not found: %s\nRun 'contindex init' to set up the structure", contextDir)
+		return nil, fmt.Errorf("context directory not found: %s\nRun 'contindex init' to set up the structure", contextDir)
 	}
 
 	// Scan for chapter files
 	chapterFiles, err := scanContextDirectory(contextDir)
 	if err != nil {
-		return fmt.Errorf("failed to scan chapter files: %w", err)
+		return nil, fmt.Errorf("failed to scan chapter files: %w", err)
 	}
 
-	if len(chapterFiles) == 0 {
-		fmt.Printf("No chapter files found in %s\n", contextDir)
-		fmt.Printf("Add .md files to the context/ directory and run update again\n")
-		return nil
+	analyzeChapters(chapterFiles, tokenizer)
+	for _, file := range chapterFiles {
+		if len(file.Globs) == 0 {
+			file.Globs = mentionedGlobs(projectConfig.ProjectRoot, contextDir, file.Content)
+		}
 	}
+	return chapterFiles, nil
+}
 
-	logVerbose(cmd, "Found %d chapter files", len(chapterFiles))
+// mentionedGlobs turns the project files and directories a chapter mentions
+// into globs, so tools that scope rules by file attach the chapter while
+// those files are edited. Paths that do not exist and chapters are skipped.
+func mentionedGlobs(projectRoot, contextDir, content string) []string {
+	var globs []string
+	for _, mentioned := range classifier.MentionedPaths(content) {
+		filePath := filepath.Join(projectRoot, filepath.FromSlash(mentioned))
+		if rel, err := filepath.Rel(contextDir, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+			continue
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			mentioned = strings.TrimSuffix(mentioned, "/") + "/**"
+		}
+		globs = append(globs, mentioned)
+	}
+	return globs
+}
 
-	// Get current index file path
-	indexFile, err := config.GetMainFileForTemplate(updateTemplate, projectPath)
-	if err != nil {
-		return fmt.Errorf("failed to determine index file path: %w", err)
+// indexFiles returns the paths of the index files, without chapter files
+func (s *indexState) indexFiles() []string {
+	var paths []string
+	for _, index := range s.indexes {
+		if index.chapter == "" {
+			paths = append(paths, index.path)
+		}
 	}
+	return paths
+}
 
-	// Check if update is needed (unless forced)
-	if !forceUpdate {
-		if needsUpdate, err := checkIfUpdateNeeded(indexFile, chapterFiles); err != nil {
-			logVerbose(cmd, "Warning: could not check update status: %v", err)
-		} else if !needsUpdate {
-			fmt.Printf("Index file is up to date. Use --force to regenerate anyway.\n")
-			return nil
+// files returns the paths of the index files and chapter files
+func (s *indexState) files() []string {
+	var paths []string
+	for _, index := range s.indexes {
+		paths = append(paths, index.path)
+	}
+	return paths
+}
+
+// upToDate reports whether the chapters and index files match the manifest
+// and every rendered index matches the file on disk
+func (s *indexState) upToDate() bool {
+	if s.previous == nil || !s.changes.Empty() || len(s.stale) > 0 {
+		return false
+	}
+	for _, index := range s.indexes {
+		if index.current != index.rendered || manifest.Hash([]byte(index.current)) != s.previous.IndexHash(index.ref) {
+			return false
 		}
 	}
+	return true
+}
 
-	// Generate updated index using template system
-	projectConfig := config.DefaultConfig(projectPath)
-	if err := projectConfig.UpdateForTemplate(updateTemplate); err != nil {
-		return fmt.Errorf("failed to configure template: %w", err)
+// write saves each rendered index that differs from the file on disk and
+// records the manifest. It returns the index files that were rewritten.
+func (s *indexState) write() ([]string, error) {
+	var written []string
+	for _, index := range s.indexes {
+		if index.rendered == index.current {
+			continue
+		}
+		if err := writeIndexFile(index.path, index.rendered); err != nil {
+			return written, fmt.Errorf("failed to update index file %s: %w", index.path, err)
+		}
+		written = append(written, index.path)
+	}
+	if err := s.removeStale(); err != nil {
+		return written, err
 	}
 
-	// Use template system to regenerate the index
+	if err := saveManifest(s.config.ProjectRoot, s.previous, s.current, s.files(), s.stale); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+// removeStale deletes the chapter files of removed chapters. A file edited
+// since update wrote it is kept, and no longer tracked, rather than lose the
+// edits.
+func (s *indexState) removeStale() error {
+	s.removed = nil
+	for _, filePath := range s.stale {
+		ref, err := relativeSlashPath(s.config.ProjectRoot, filePath)
+		if err != nil {
+			return err
+		}
+		hash, err := manifest.HashFile(filePath)
+		if err != nil {
+			return err
+		}
+		if hash != s.previous.IndexHash(ref) {
+			fmt.Printf("Keeping %s: its chapter was removed, but the file was edited since the last update\n", filePath)
+			continue
+		}
+		if err := os.Remove(filePath); err != nil {
+			return fmt.Errorf("failed to remove chapter file %s: %w", filePath, err)
+		}
+		s.removed = append(s.removed, filePath)
+	}
+	return nil
+}
+
+// renderIndex returns the index file as it is on disk and as update would
+// write it. A missing index is rendered from the template; an existing one is
+// never regenerated, so hand-written content outside the managed region
+// survives.
+func renderIndex(projectConfig *config.ProjectConfig, chapters []template.Chapter) (string, string, error) {
+	indexFile := projectConfig.MainFile
 	templateManager := template.New()
-	if err := templateManager.ApplyTemplate(projectConfig); err != nil {
-		return fmt.Errorf("failed to apply template: %w", err)
+
+	var currentIndex, base string
+	content, err := os.ReadFile(indexFile)
+	switch {
+	case os.IsNotExist(err):
+		rendered, err := templateManager.Render(projectConfig, chapters)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to apply template: %w", err)
+		}
+		return "", rendered, nil
+	case err != nil:
+		return "", "", fmt.Errorf("failed to read index file: %w", err)
+	default:
+		currentIndex = string(content)
+		base = currentIndex
+		if !template.HasRegion(currentIndex, template.ChaptersRegion) {
+			if !migrateIndex {
+				return "", "", fmt.Errorf(`index file %s has no managed chapter region, so update cannot refresh it without overwriting your edits.
+To migrate, either:
+  - run 'contindex update --migrate' to wrap the existing chapter list in region markers, or
+  - add these lines where the chapter list should go and run update again:
+      %s
+      %s`, indexFile, template.RegionBegin(template.ChaptersRegion), template.RegionEnd(template.ChaptersRegion))
+			}
+
+			var description string
+			base, description = template.MigrateChaptersRegion(currentIndex)
+			fmt.Printf("Migrating %s: %s\n", indexFile, description)
+		}
 	}
 
-	// Update template with chapter filenames (already semantic from AI)
-	if err := UpdateTemplateWithChapters(indexFile, chapterFiles, projectConfig.ContextDir); err != nil {
-		return fmt.Errorf("failed to update template with chapters: %w", err)
+	chapterList, err := templateManager.RenderRegion(projectConfig, chapters, template.ChaptersRegion)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render chapter list: %w", err)
+	}
+	renderedIndex, err := template.ReplaceRegion(base, template.ChaptersRegion, chapterList)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid index file %s: %w", indexFile, err)
 	}
+	return currentIndex, renderedIndex, nil
+}
 
-	// Success message
-	printUpdateSuccess(indexFile, chapterFiles)
+// checkIndexDrift prints the difference between each index on disk and its
+// rendered content, failing when any differ or a chapter file would be
+// removed. Nothing is written.
+func checkIndexDrift(cmd *cobra.Command, state *indexState) error {
+	var stale []string
+	for _, index := range state.indexes {
+		if index.current == index.rendered {
+			fmt.Printf("✓ Index file %s is up to date\n", index.path)
+			continue
+		}
+		fmt.Print(diff.Unified(index.path, index.path+" (after update)", index.current, index.rendered, diff.DefaultContext))
+		stale = append(stale, index.path)
+	}
+	for _, filePath := range state.stale {
+		fmt.Printf("- %s (chapter removed; update deletes it)\n", filePath)
+		stale = append(stale, filePath)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
 
-	return nil
+	// Drift is a result, not a usage mistake
+	cmd.SilenceUsage = true
+	return fmt.Errorf("index files out of date: %s; run 'contindex update' to regenerate them", strings.Join(stale, ", "))
 }
 
-func checkIfUpdateNeeded(indexFile string, chapterFiles []*classifier.ContextFile) (bool, error) {
-	// Check if index file exists
-	indexStat, err := os.Stat(indexFile)
-	if os.IsNotExist(err) {
-		return true, nil // Index doesn't exist, update needed
+// warnOversizedIndexes points out index files larger than their template's
+// token budget, which defeats loading chapters selectively
+func warnOversizedIndexes(indexes []*indexFile, tokenizer classifier.Tokenizer) {
+	for _, index := range indexes {
+		if index.limit == 0 {
+			continue
+		}
+		if tokens := tokenizer.Count(index.rendered); tokens > index.limit {
+			fmt.Printf("\nWarning: %s is %s, over its template's budget of %d tokens.\n", index.path, formatTokens(tokens, tokenizer), index.limit)
+			fmt.Printf("Consider grouping chapters into subdirectories or shortening their summaries.\n")
+		}
 	}
+}
+
+// printChapterFileSync reports the chapter files written and removed for
+// templates such as cursor-rules
+func printChapterFileSync(state *indexState, written []string) {
+	wasWritten := make(map[string]bool)
+	for _, filePath := range written {
+		wasWritten[filePath] = true
+	}
+
+	total, updated := 0, 0
+	for _, index := range state.indexes {
+		if index.chapter == "" {
+			continue
+		}
+		total++
+		if wasWritten[index.path] {
+			updated++
+		}
+	}
+	if total == 0 && len(state.removed) == 0 {
+		return
+	}
+	fmt.Printf("\nChapter files: %d written, %d unchanged, %d removed\n", updated, total-updated, len(state.removed))
+	for _, filePath := range state.removed {
+		fmt.Printf("   - %s\n", filePath)
+	}
+}
+
+// writeIndexFile writes the index, creating its directory for templates such as copilot
+func writeIndexFile(indexFile, content string) error {
+	if err := os.MkdirAll(filepath.Dir(indexFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(indexFile, []byte(content), 0644)
+}
+
+// buildManifest records the hash, size and tokens of every chapter. Paths are
+// stored relative to the project root so the manifest does not depend on the
+// working directory.
+func buildManifest(projectPath, contextDir string, chapterFiles []*classifier.ContextFile, tokenizer classifier.Tokenizer) (*manifest.Manifest, error) {
+	contextRef, err := relativeSlashPath(projectPath, contextDir)
 	if err != nil {
-		return true, err // Can't check, assume update needed
+		return nil, err
 	}
 
-	// Check if any chapter file is newer than index
+	current := &manifest.Manifest{
+		ContextDir: contextRef,
+		Tokenizer:  tokenizer.Name(),
+	}
 	for _, file := range chapterFiles {
-		filePath := filepath.Join("context", file.FileName)
-		fileStat, err := os.Stat(filePath)
+		data, err := os.ReadFile(filepath.Join(contextDir, filepath.FromSlash(file.Path())))
 		if err != nil {
-			continue // Skip if file doesn't exist
+			return nil, fmt.Errorf("failed to read chapter %s: %w", file.Path(), err)
 		}
-		if fileStat.ModTime().After(indexStat.ModTime()) {
-			return true, nil // Chapter file is newer
+		current.Chapters = append(current.Chapters, manifest.Chapter{
+			Path:   file.Path(),
+			Hash:   manifest.Hash(data),
+			Size:   int64(len(data)),
+			Tokens: file.TokenCount,
+		})
+	}
+	return current, nil
+}
+
+// saveManifest records the hash of each index file as written and saves the
+// manifest. Index files of other templates recorded in the previous manifest
+// keep their hashes, except the untracked ones, such as stale chapter files.
+func saveManifest(projectPath string, previous, current *manifest.Manifest, indexFiles, untracked []string) error {
+	current.Indexes = nil
+	skip := make(map[string]bool)
+	for _, indexFile := range indexFiles {
+		ref, err := relativeSlashPath(projectPath, indexFile)
+		if err != nil {
+			return err
+		}
+		hash, err := manifest.HashFile(indexFile)
+		if err != nil {
+			return err
+		}
+		current.Indexes = append(current.Indexes, manifest.Index{Path: ref, Hash: hash})
+		skip[ref] = true
+	}
+
+	for _, filePath := range untracked {
+		ref, err := relativeSlashPath(projectPath, filePath)
+		if err != nil {
+			return err
+		}
+		skip[ref] = true
+	}
+	if previous != nil {
+		for _, index := range previous.Indexes {
+			if !skip[index.Path] {
+				current.Indexes = append(current.Indexes, index)
+			}
 		}
 	}
+	return current.Save(projectPath)
+}
+
+func relativeSlashPath(base, target string) (string, error) {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s relative to %s: %w", target, base, err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// printChangeSummary lists chapter changes since the manifest was written
+func printChangeSummary(previous *manifest.Manifest, changes manifest.Changes) {
+	if previous == nil {
+		fmt.Printf("No manifest found; recording %d chapters in %s\n\n", len(changes.Added), manifest.FileName)
+		return
+	}
+	if changes.Empty() {
+		fmt.Printf("Chapters unchanged; the index file differs from the last update\n\n")
+		return
+	}
 
-	return false, nil // No update needed
+	fmt.Printf("Changes since last update: %s\n", changes.Summary())
+	for _, path := range changes.Added {
+		fmt.Printf("   + %s\n", path)
+	}
+	for _, path := range changes.Removed {
+		fmt.Printf("   - %s\n", path)
+	}
+	for _, rename := range changes.Renamed {
+		fmt.Printf("   > %s -> %s\n", rename.From, rename.To)
+	}
+	for _, path := range changes.Modified {
+		fmt.Printf("   ~ %s\n", path)
+	}
+	fmt.Printf("\n")
 }
 
-func printUpdateSuccess(indexFile string, chapterFiles []*classifier.ContextFile) {
-	fmt.Printf("✓ Successfully updated index file: %s\n\n", indexFile)
+func printUpdateSuccess(indexFiles []string, contextRef string, chapterFiles []*classifier.ContextFile, tokenizer classifier.Tokenizer) {
+	if len(indexFiles) == 1 {
+		fmt.Printf("✓ Successfully updated index file: %s\n\n", indexFiles[0])
+	} else {
+		fmt.Printf("✓ Successfully updated %d index files: %s\n\n", len(indexFiles), strings.Join(indexFiles, ", "))
+	}
 
+	totalWords := 0
+	totalTokens := 0
 	fmt.Printf("Chapter files referenced:\n")
 	for i, file := range chapterFiles {
-		fmt.Printf("%d. context/%s\n", i+1, file.FileName)
+		fmt.Printf("%d. %s/%s (%s)\n", i+1, contextRef, file.Path(), formatTokens(file.TokenCount, tokenizer))
+		totalWords += file.WordCount
+		totalTokens += file.TokenCount
 	}
 
 	fmt.Printf("\nTotal chapters: %d\n", len(chapterFiles))
+	fmt.Printf("Total content: %d words, %s\n", totalWords, formatTokens(totalTokens, tokenizer))
 	fmt.Printf("\nAI tools can now reference the updated index to load specific chapters.\n")
 }
 
-// scanContextDirectory scans the context directory for .md files
+// scanContextDirectory scans the context directory and its subdirectories for .md files
 func scanContextDirectory(contextDir string) ([]*classifier.ContextFile, error) {
-	entries, err := os.ReadDir(contextDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read context directory: %w", err)
-	}
-
 	var contextFiles []*classifier.ContextFile
 
-	for _, entry := range entries {
+	err := filepath.WalkDir(contextDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
-			continue
+			return nil
+		}
+
+		relDir, err := filepath.Rel(contextDir, filepath.Dir(filePath))
+		if err != nil {
+			return err
+		}
+		group := filepath.ToSlash(relDir)
+		if group == "." {
+			group = ""
 		}
 
-		// Simple ContextFile with just filename - no analysis needed
 		contextFile := &classifier.ContextFile{
 			FileName: entry.Name(),
+			Group:    group,
+		}
+		if err := readChapter(filePath, contextFile); err != nil {
+			return err
 		}
 
 		contextFiles = append(contextFiles, contextFile)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context directory: %w", err)
 	}
 
+	sortBySourceOrder(contextFiles)
 	return contextFiles, nil
 }
+
+// sortBySourceOrder lists chapters in the order of the file they were converted
+// from. Chapters without source lines, such as ones added by hand, follow in
+// their current order.
+func sortBySourceOrder(contextFiles []*classifier.ContextFile) {
+	sort.SliceStable(contextFiles, func(i, j int) bool {
+		a, b := contextFiles[i].StartLine, contextFiles[j].StartLine
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+}
+
+// readChapter loads a chapter body and fills its title, position and globs
+// from the front matter written by convert. Without front matter, the H1
+// becomes the title. Summary, key terms and tokens follow the body instead, as
+// it may have been edited since the front matter was written.
+func readChapter(filePath string, contextFile *classifier.ContextFile) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read chapter %s: %w", filePath, err)
+	}
+
+	metadata, body, err := frontmatter.ParseChapter(string(content))
+	if err != nil {
+		return fmt.Errorf("invalid front matter in %s: %w", filePath, err)
+	}
+
+	contextFile.Title, contextFile.Content = splitChapterTitle(body)
+	if metadata == nil {
+		return nil
+	}
+
+	if metadata.Title != "" {
+		contextFile.Title = metadata.Title
+	}
+	contextFile.HeadingPath = metadata.HeadingPath
+	contextFile.StartLine = metadata.StartLine
+	contextFile.EndLine = metadata.EndLine
+	contextFile.Part = metadata.Part
+	contextFile.Globs = metadata.Globs
+	return nil
+}
+
+// splitChapterTitle separates a leading H1 from the chapter body
+func splitChapterTitle(body string) (string, string) {
+	blocks := markdown.Parse(body)
+	if len(blocks) == 0 || blocks[0].Kind != markdown.BlockHeading || blocks[0].Level != 1 {
+		return "", strings.TrimSpace(body)
+	}
+	lines := markdown.SplitLines(body)
+	return blocks[0].Text, strings.TrimSpace(strings.Join(lines[blocks[0].EndLine:], "\n"))
+}
+
+// analyzeChapters counts words and tokens of every chapter and derives its
+// summary and key terms from the current body. Key terms are ranked against
+// all chapters, as in convert.
+func analyzeChapters(chapterFiles []*classifier.ContextFile, tokenizer classifier.Tokenizer) {
+	var contents []string
+	for _, file := range chapterFiles {
+		contents = append(contents, file.Content)
+	}
+	keyTerms := classifier.ExtractKeyTerms(contents, classifier.MaxKeyTerms)
+
+	for i, file := range chapterFiles {
+		file.WordCount = len(strings.Fields(file.Content))
+		file.TokenCount = tokenizer.Count(file.Content)
+		file.Summary = classifier.Summarize(file.Content, classifier.MaxSummaryLength)
+		file.KeyTerms = keyTerms[i]
+	}
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/angelcodes95/contindex/internal/classifier"
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/manifest"
+	"github.com/angelcodes95/contindex/internal/template"
+)
+
+func TestSortBySourceOrder(t *testing.T) {
+	files := []*classifier.ContextFile{
+		{FileName: "notes.md"},
+		{FileName: "deploy.md", StartLine: 30},
+		{FileName: "extra.md"},
+		{FileName: "setup.md", StartLine: 5},
+	}
+
+	sortBySourceOrder(files)
+
+	var got []string
+	for _, file := range files {
+		got = append(got, file.FileName)
+	}
+	want := []string{"setup.md", "deploy.md", "notes.md", "extra.md"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("sortBySourceOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateRecomputesEditedChapters(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+
+	// A project template that lists the summary and token count of each chapter
+	templateDir := filepath.Join(root, ".contindex", "templates", "tokens")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "template.json"), []byte(`{"main_file": "INDEX.md"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chapterList := "{{range .Chapters}}{{.Summary}} ({{tokens .Tokens}})\n{{end}}"
+	if err := os.WriteFile(filepath.Join(templateDir, "template.md"), []byte(chapterList), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projectConfig, err := config.Load(root, config.Settings{Template: config.TemplateList{"tokens"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenizer, err := classifier.NewTokenizer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(projectConfig.ContextDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	frontMatter := "---\ntitle: \"Setup\"\nstart_line: 3\nend_line: 6\nsummary: \"Install Node before running the app.\"\ntokens: 9\ntokenizer: \"heuristic\"\n---\n\n# Setup\n\n"
+	chapterPath := filepath.Join(projectConfig.ContextDir, "setup.md")
+	index := func(body string) string {
+		t.Helper()
+		if err := os.WriteFile(chapterPath, []byte(frontMatter+body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		state, err := loadIndexState(projectConfig, tokenizer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return state.indexes[0].rendered
+	}
+
+	before := index("Install Node before running the app.\n")
+	after := index("Install Go 1.22 and run make to build the binary, then run the migrations against a local Postgres database.\n")
+
+	if !strings.Contains(after, "Install Go 1.22 and run make") || strings.Contains(after, "Install Node") {
+		t.Errorf("index keeps the summary from the front matter:\n%s", after)
+	}
+	wantTokens := fmt.Sprintf("~%d tokens", tokenizer.Count("Install Go 1.22 and run make to build the binary, then run the migrations against a local Postgres database."))
+	if !strings.Contains(after, wantTokens) || strings.Contains(before, wantTokens) {
+		t.Errorf("index token count does not follow the edited body, want %q:\n%s", wantTokens, after)
+	}
+}
+
+func TestSaveManifestKeepsOtherIndexes(t *testing.T) {
+	root := t.TempDir()
+	claude := filepath.Join(root, "CLAUDE.md")
+	stale := filepath.Join(root, ".cursor", "rules", "old.mdc")
+	if err := os.WriteFile(claude, []byte("# Index\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := &manifest.Manifest{Indexes: []manifest.Index{
+		{Path: "CLAUDE.md", Hash: "old"},
+		{Path: "GEMINI.md", Hash: "gemini"},
+		{Path: ".cursor/rules/old.mdc", Hash: "stale"},
+	}}
+	current := &manifest.Manifest{}
+	if err := saveManifest(root, previous, current, []string{claude}, []string{stale}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []manifest.Index{
+		{Path: "CLAUDE.md", Hash: manifest.Hash([]byte("# Index\n"))},
+		{Path: "GEMINI.md", Hash: "gemini"},
+	}
+	if !reflect.DeepEqual(current.Indexes, want) {
+		t.Errorf("Indexes = %+v, want %+v", current.Indexes, want)
+	}
+}
+
+func TestRenderChapterFilesCollision(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	projectConfig, err := config.Load(root, config.Settings{Template: config.TemplateList{"cursor-rules"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := projectConfig.TargetConfigs()[0]
+	templateConfig, err := config.LookupTemplate(target.Template, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr string
+	}{
+		{"distinct names", []string{"guides/setup.md", "setup.md"}, ""},
+		{"flattened names collide", []string{"guides/setup.md", "guides-setup.md"}, "chapters guides/setup.md and guides-setup.md both map to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chapters []template.Chapter
+			for _, chapterPath := range tt.paths {
+				chapters = append(chapters, template.Chapter{Name: filepath.Base(chapterPath), Title: chapterPath, Path: chapterPath})
+			}
+			files, err := renderChapterFiles(target, templateConfig, chapters)
+			if tt.wantErr == "" {
+				if err != nil || len(files) != len(tt.paths) {
+					t.Errorf("renderChapterFiles() = %d files, %v; want %d files", len(files), err, len(tt.paths))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("renderChapterFiles() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
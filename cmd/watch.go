@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/angelcodes95/contindex/internal/classifier"
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/manifest"
+	"github.com/angelcodes95/contindex/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep the index file in sync while chapter files change",
+	Long: `Watch polls the context directory and re-runs update whenever chapter
+files are created, modified, renamed or deleted.
+
+Updates wait until the directory has been quiet for the debounce interval,
+so a burst of edits produces one update. The index file is only rewritten
+when its rendered content changes. Polling works the same on every platform
+and needs no file system notification support.
+
+Press Ctrl+C to stop watching.`,
+	RunE: runWatch,
+}
+
+var (
+	watchInterval time.Duration
+	watchDebounce time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	addProjectFlags(watchCmd)
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 500*time.Millisecond,
+		"How often to poll the context directory")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", time.Second,
+		"How long the context directory must be quiet before updating")
+}
+
+// fileStamp is what polling compares to notice a changed chapter
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	projectPath := getProjectPath(cmd)
+
+	if err := validation.ValidateDirectoryPath(projectPath); err != nil {
+		return fmt.Errorf("invalid project path: %w", err)
+	}
+	if watchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if watchDebounce < 0 {
+		return fmt.Errorf("--debounce must not be negative")
+	}
+
+	projectConfig, err := loadProjectConfig(cmd)
+	if err != nil {
+		return err
+	}
+	tokenizer, err := classifier.NewTokenizer(projectConfig.Tokenizer)
+	if err != nil {
+		return fmt.Errorf("invalid tokenizer: %w", err)
+	}
+
+	contextDir := projectConfig.ContextDir
+	if _, err := os.Stat(contextDir); os.IsNotExist(err) {
+		return fmt.Errorf("context directory not found: %s\nRun 'contindex init' to set up the structure", contextDir)
+	}
+
+	fmt.Printf("Watching %s for chapter changes (Ctrl+C to stop)\n", contextDir)
+
+	// Bring the index in sync before waiting for changes
+	syncWatchedIndex(projectConfig, tokenizer)
+	last, err := snapshotChapters(contextDir)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var changedAt time.Time
+	for {
+		select {
+		case <-stop:
+			fmt.Printf("\nStopped watching %s\n", contextDir)
+			return nil
+		case now := <-ticker.C:
+			snapshot, err := snapshotChapters(contextDir)
+			if err != nil {
+				logWatch("%v", err)
+				continue
+			}
+
+			// Every change restarts the debounce interval
+			if !sameSnapshot(last, snapshot) {
+				last = snapshot
+				changedAt = now
+				logVerbose(cmd, "Change detected in %s", contextDir)
+				continue
+			}
+			if !changedAt.IsZero() && now.Sub(changedAt) >= watchDebounce {
+				changedAt = time.Time{}
+				syncWatchedIndex(projectConfig, tokenizer)
+			}
+		}
+	}
+}
+
+// syncWatchedIndex runs the update pipeline and logs the outcome. Errors are
+// logged rather than returned so a broken chapter does not stop the watcher.
+func syncWatchedIndex(projectConfig *config.ProjectConfig, tokenizer classifier.Tokenizer) {
+	state, err := loadIndexState(projectConfig, tokenizer)
+	if err != nil {
+		logWatch("update failed: %v", err)
+		return
+	}
+	if len(state.chapters) == 0 {
+		logWatch("no chapter files in %s", projectConfig.ContextDir)
+		return
+	}
+	if state.upToDate() {
+		logWatch("%s up to date", strings.Join(state.indexFiles(), ", "))
+		return
+	}
+
+	logChanges(state.previous, state.changes)
+
+	written, err := state.write()
+	for _, indexFile := range written {
+		logWatch("updated %s (%d chapters)", indexFile, len(state.chapters))
+	}
+	if err != nil {
+		logWatch("update failed: %v", err)
+		return
+	}
+	if len(written) == 0 {
+		logWatch("%s unchanged", strings.Join(state.indexFiles(), ", "))
+	}
+}
+
+// logChanges logs each chapter change since the manifest was written
+func logChanges(previous *manifest.Manifest, changes manifest.Changes) {
+	if previous == nil {
+		logWatch("no manifest found; recording %d chapters", len(changes.Added))
+		return
+	}
+	for _, path := range changes.Added {
+		logWatch("created %s", path)
+	}
+	for _, path := range changes.Removed {
+		logWatch("deleted %s", path)
+	}
+	for _, rename := range changes.Renamed {
+		logWatch("renamed %s -> %s", rename.From, rename.To)
+	}
+	for _, path := range changes.Modified {
+		logWatch("modified %s", path)
+	}
+}
+
+func logWatch(format string, args ...interface{}) {
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// snapshotChapters records the size and modification time of every chapter
+func snapshotChapters(contextDir string) (map[string]fileStamp, error) {
+	snapshot := make(map[string]fileStamp)
+	err := filepath.WalkDir(contextDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		snapshot[filePath] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", contextDir, err)
+	}
+	return snapshot, nil
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		other, ok := b[path]
+		if !ok || other.size != stamp.size || !other.modTime.Equal(stamp.modTime) {
+			return false
+		}
+	}
+	return true
+}
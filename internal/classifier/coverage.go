@@ -0,0 +1,92 @@
+package classifier
+
+import (
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/markdown"
+)
+
+// LineRange is an inclusive range of 1-based source line numbers
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// CoverageReport describes how much of the source file is carried into chapters or the index
+type CoverageReport struct {
+	TotalLines   int         // Non-blank lines in the source file
+	CoveredLines int         // Non-blank lines mapped to a chapter or the index
+	Uncovered    []LineRange // Ranges of non-blank lines that would be lost
+	lines        []string
+}
+
+// Lossless reports whether every non-blank source line is covered
+func (r *CoverageReport) Lossless() bool {
+	return len(r.Uncovered) == 0
+}
+
+// Percent returns the share of non-blank source lines that are covered
+func (r *CoverageReport) Percent() float64 {
+	if r.TotalLines == 0 {
+		return 100
+	}
+	return float64(r.CoveredLines) * 100 / float64(r.TotalLines)
+}
+
+// FirstLine returns the first non-blank line of an uncovered range for display
+func (r *CoverageReport) FirstLine(lineRange LineRange) string {
+	for n := lineRange.Start; n <= lineRange.End && n <= len(r.lines); n++ {
+		if text := strings.TrimSpace(r.lines[n-1]); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// Coverage maps every source line to one of the generated files using their
+// StartLine/EndLine ranges. A leading H1 document title is only covered by
+// the preamble, which carries it as its title in a chapter or in the index;
+// without a preamble it is reported as lost.
+func (fa *FileAnalyzer) Coverage(files []*ContextFile) *CoverageReport {
+	return CheckCoverage(fa.content, files)
+}
+
+// CheckCoverage computes the coverage of source content by a set of generated files
+func CheckCoverage(content string, files []*ContextFile) *CoverageReport {
+	lines := markdown.SplitLines(content)
+	covered := make([]bool, len(lines)+1)
+
+	markCovered := func(start, end int) {
+		for n := start; n <= end && n <= len(lines); n++ {
+			if n >= 1 {
+				covered[n] = true
+			}
+		}
+	}
+
+	for _, file := range files {
+		markCovered(file.StartLine, file.EndLine)
+	}
+
+	report := &CoverageReport{lines: lines}
+	var open *LineRange
+	for n := 1; n <= len(lines); n++ {
+		if strings.TrimSpace(lines[n-1]) == "" {
+			continue
+		}
+		report.TotalLines++
+		if covered[n] {
+			report.CoveredLines++
+			open = nil
+			continue
+		}
+		if open != nil {
+			open.End = n
+			continue
+		}
+		report.Uncovered = append(report.Uncovered, LineRange{Start: n, End: n})
+		open = &report.Uncovered[len(report.Uncovered)-1]
+	}
+
+	return report
+}
@@ -0,0 +1,111 @@
+package classifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckCoverage(t *testing.T) {
+	content := "# Title\n\nIntro paragraph\n\n## Kept\nbody of the kept section\n\n## Dropped\nshort\n"
+
+	tests := []struct {
+		name      string
+		files     []*ContextFile
+		covered   int
+		uncovered []LineRange
+	}{
+		{
+			name: "dropped section is reported",
+			files: []*ContextFile{
+				{StartLine: 1, EndLine: 4, Preamble: true},
+				{StartLine: 5, EndLine: 7},
+			},
+			covered:   4,
+			uncovered: []LineRange{{Start: 8, End: 9}},
+		},
+		{
+			name: "every line covered",
+			files: []*ContextFile{
+				{StartLine: 1, EndLine: 4, Preamble: true},
+				{StartLine: 5, EndLine: 7},
+				{StartLine: 8, EndLine: 9},
+			},
+			covered: 6,
+		},
+		{
+			name: "document title without a preamble is lost",
+			files: []*ContextFile{
+				{StartLine: 3, EndLine: 9},
+			},
+			covered:   5,
+			uncovered: []LineRange{{Start: 1, End: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckCoverage(content, tt.files)
+			if report.TotalLines != 6 {
+				t.Errorf("CheckCoverage() TotalLines = %d, want 6", report.TotalLines)
+			}
+			if report.CoveredLines != tt.covered {
+				t.Errorf("CheckCoverage() CoveredLines = %d, want %d", report.CoveredLines, tt.covered)
+			}
+			if len(report.Uncovered) != len(tt.uncovered) {
+				t.Fatalf("CheckCoverage() Uncovered = %v, want %v", report.Uncovered, tt.uncovered)
+			}
+			for i, r := range tt.uncovered {
+				if report.Uncovered[i] != r {
+					t.Errorf("CheckCoverage() Uncovered[%d] = %v, want %v", i, report.Uncovered[i], r)
+				}
+			}
+			if report.Lossless() != (len(tt.uncovered) == 0) {
+				t.Errorf("CheckCoverage() Lossless() = %v", report.Lossless())
+			}
+		})
+	}
+}
+
+func TestCoverageOfDocumentTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		lossless bool
+	}{
+		{
+			name:     "title with only body sections is lost",
+			content:  "# Acme Engineering Rules\n\n## Setup\n\nInstall Go and run make to build the binary and its assets.\n\n## Testing\n\nRun go test before every commit and keep the suite green.\n",
+			lossless: false,
+		},
+		{
+			name:     "title is kept by the preamble",
+			content:  "# Acme Engineering Rules\n\nEvery change needs a review before it merges.\n\n## Setup\n\nInstall Go and run make to build the binary and its assets.\n",
+			lossless: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceFile := filepath.Join(t.TempDir(), "CLAUDE.md")
+			if err := os.WriteFile(sourceFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			fa := NewFileAnalyzer(sourceFile)
+			files, err := fa.AnalyzeAndGenerate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// --strict fails exactly when the report is not lossless
+			report := fa.Coverage(files)
+			if report.Lossless() != tt.lossless {
+				t.Errorf("Coverage().Lossless() = %v, want %v (uncovered %v)", report.Lossless(), tt.lossless, report.Uncovered)
+			}
+			if !tt.lossless && report.FirstLine(report.Uncovered[0]) != "# Acme Engineering Rules" {
+				t.Errorf("Coverage() did not report the title as lost: %v", report.Uncovered)
+			}
+		})
+	}
+}
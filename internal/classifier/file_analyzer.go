@@ -1,13 +1,13 @@
 package classifier
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
-	"regexp"
+	"path"
 	"strings"
 
+	"github.com/angelcodes95/contindex/internal/markdown"
 	"github.com/angelcodes95/contindex/internal/validation"
 )
 
@@ -18,13 +18,25 @@ const (
 	TokenEstimationRatio = 4  // Estimated characters per token
 )
 
+// PreambleFileName is the chapter file used for text that precedes the first section heading
+const PreambleFileName = "project-overview.md"
+
+// DefaultPreambleTitle is used when the preamble has no H1 title of its own
+const DefaultPreambleTitle = "Overview"
+
 // ContentSection represents a section of content with metadata
 type ContentSection struct {
-	Title     string // Section title extracted from headers
-	Content   string // The actual content text
-	StartLine int    // Starting line number in source file
-	EndLine   int    // Ending line number in source file
-	WordCount int    // Word count for this section
+	Title       string   // Section title extracted from headers
+	Level       int      // Heading level of the section title
+	HeadingPath []string // Titles of the enclosing headings, outermost first
+	TopSection  string   // Title of the outermost enclosing section, empty for top-level sections
+	Content     string   // The actual content text
+	StartLine   int      // Starting line number in source file
+	EndLine     int      // Ending line number in source file
+	WordCount   int      // Word count for this section
+	Preamble    bool     // True for text that precedes the first section heading
+	Part        int      // 1-based part number when a section was split, 0 otherwise
+	bodyStart   int      // First line after the section heading
 }
 
 // ContextFile represents a single context file with descriptive naming
@@ -32,23 +44,50 @@ type ContextFile struct {
 	FileName   string   // Descriptive filename based on content
 	Content    string   // The actual file content
 	WordCount  int      // Word count for the file
-	TokenCount int      // Estimated token count
+	TokenCount int      // Token count from the analyzer's tokenizer
 	Summary    string   // Brief content summary for indexing
 	KeyTerms   []string // Key terms extracted from content
+	Globs      []string // File patterns the chapter applies to
+	Confidence float64  // Confidence of the classification behind the name (0-1)
+	Title      string   // Original section title
+	StartLine  int      // Starting line number in source file
+	EndLine    int      // Ending line number in source file
+	Preamble   bool     // True when the file holds the source preamble
+	Part       int      // 1-based part number when a section was split, 0 otherwise
+
+	HeadingPath      []string // Titles of the enclosing headings, outermost first
+	OriginalFileName string   // Generated name before collision resolution, empty if unchanged
+	Group            string   // Subdirectory of the context dir holding the file, empty for the top level
+	DocumentTitle    bool     // True when a preamble's Title is the H1 at the top of the source
+}
+
+// Path returns the slash-separated path of the file relative to the context dir
+func (cf *ContextFile) Path() string {
+	return path.Join(cf.Group, cf.FileName)
 }
 
 // FileAnalyzer processes monolithic files and generates descriptive individual files
 type FileAnalyzer struct {
-	SourceFile   string            // Path to source monolithic file
+	SourceFile string      // Path to source monolithic file
+	Vocabulary *Vocabulary // Terms used to classify sections for naming
+	Tokenizer  Tokenizer   // Counts chapter tokens
+
+	MaxChapterTokens int  // Split sections above this many tokens, 0 for no limit
+	MinChapterTokens int  // Merge sections below this many tokens, 0 to drop tiny sections instead
+	Nested           bool // Put top-level sections with subsections in their own subdirectory
+
 	content      string            // Cached source content
 	sections     []*ContentSection // Parsed sections from source
 	contextFiles []*ContextFile    // Generated context files
+	decisions    []SizingDecision  // Splits and merges made to fit the token budget
 }
 
 // New creates a new FileAnalyzer instance
 func NewFileAnalyzer(sourceFile string) *FileAnalyzer {
 	return &FileAnalyzer{
 		SourceFile: sourceFile,
+		Vocabulary: DefaultVocabulary(),
+		Tokenizer:  heuristicTokenizer{},
 	}
 }
 
@@ -80,78 +119,193 @@ func (fa *FileAnalyzer) parseSourceFile() error {
 	}
 
 	fa.content = string(content)
-	scanner := bufio.NewScanner(strings.NewReader(fa.content))
+	if fa.MaxChapterTokens > 0 || fa.MinChapterTokens > 0 {
+		fa.sections = fa.buildSizedSections(fa.content)
+	} else {
+		fa.sections = buildSections(fa.content)
+	}
+	return nil
+}
+
+// buildSections splits Markdown content into sections at level 2+ heading nodes.
+// Headings are taken from the block parser, so lines inside fenced or indented
+// code, HTML blocks and block quotes never start a section.
+func buildSections(content string) []*ContentSection {
+	lines := markdown.SplitLines(content)
+	headings := markdown.Headings(markdown.Parse(content))
 
+	var sections []*ContentSection
+	if preamble := buildPreamble(lines, headings); preamble != nil {
+		sections = append(sections, preamble)
+	}
+	return append(sections, dropTinySections(splitSections(lines, headings))...)
+}
+
+// dropTinySections keeps only sections with meaningful content
+func dropTinySections(sections []*ContentSection) []*ContentSection {
+	var kept []*ContentSection
+	for _, section := range sections {
+		if section.WordCount >= MinWordCountForFile {
+			kept = append(kept, section)
+		}
+	}
+	return kept
+}
+
+// splitSections returns one section per level 2+ heading, including empty ones
+func splitSections(lines []string, headings []*markdown.Block) []*ContentSection {
 	var sections []*ContentSection
 	var currentSection *ContentSection
-	var contentBuffer strings.Builder
-	lineNum := 0
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		lineNum++
+	closeSection := func(endLine int) {
+		if currentSection == nil {
+			return
+		}
+		currentSection.Content = joinLines(lines, currentSection.bodyStart, endLine)
+		currentSection.EndLine = endLine
+		currentSection.WordCount = len(strings.Fields(currentSection.Content))
+		sections = append(sections, currentSection)
+	}
+
+	// Track enclosing headings so each section knows its ancestry
+	var ancestors []*markdown.Block
+	for i, heading := range headings {
+		for len(ancestors) > 0 && ancestors[len(ancestors)-1].Level >= heading.Level {
+			ancestors = ancestors[:len(ancestors)-1]
+		}
+		if i == 0 && isDocumentTitle(lines, heading) {
+			continue
+		}
 
-		// Detect markdown headers (## or ###)
-		if strings.HasPrefix(strings.TrimSpace(line), "##") {
+		if heading.Level >= 2 {
 			// Save previous section
-			if currentSection != nil {
-				currentSection.Content = strings.TrimSpace(contentBuffer.String())
-				currentSection.EndLine = lineNum - 1
-				currentSection.WordCount = len(strings.Fields(currentSection.Content))
-
-				// Only keep sections with meaningful content
-				if currentSection.WordCount >= MinWordCountForFile {
-					sections = append(sections, currentSection)
-				}
-			}
+			closeSection(heading.StartLine - 1)
 
 			// Start new section
-			title := strings.TrimSpace(strings.TrimLeft(line, "#"))
 			currentSection = &ContentSection{
-				Title:     title,
-				StartLine: lineNum,
+				Title:       heading.Text,
+				Level:       heading.Level,
+				HeadingPath: headingTitles(ancestors),
+				TopSection:  topSectionTitle(ancestors),
+				StartLine:   heading.StartLine,
+				bodyStart:   heading.EndLine + 1,
 			}
-			contentBuffer.Reset()
-		} else if currentSection != nil {
-			contentBuffer.WriteString(line + "\n")
 		}
+
+		ancestors = append(ancestors, heading)
 	}
 
 	// Handle final section
-	if currentSection != nil {
-		currentSection.Content = strings.TrimSpace(contentBuffer.String())
-		currentSection.EndLine = lineNum
-		currentSection.WordCount = len(strings.Fields(currentSection.Content))
+	closeSection(len(lines))
+
+	return sections
+}
 
-		if currentSection.WordCount >= MinWordCountForFile {
-			sections = append(sections, currentSection)
+// buildPreamble captures the text above the first section heading, such as the
+// document title, project overview and global rules. A leading H1 becomes the
+// preamble title rather than part of its body.
+func buildPreamble(lines []string, headings []*markdown.Block) *ContentSection {
+	end := len(lines)
+	for _, heading := range headings {
+		if heading.Level >= 2 {
+			end = heading.StartLine - 1
+			break
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error scanning file: %w", err)
+	title := DefaultPreambleTitle
+	bodyStart := 1
+	if len(headings) > 0 && isDocumentTitle(lines, headings[0]) && headings[0].StartLine <= end {
+		title = headings[0].Text
+		bodyStart = headings[0].EndLine + 1
 	}
 
-	fa.sections = sections
-	return nil
+	body := joinLines(lines, bodyStart, end)
+	if body == "" {
+		return nil
+	}
+
+	return &ContentSection{
+		Title:     title,
+		Level:     1,
+		Content:   body,
+		StartLine: 1,
+		EndLine:   end,
+		WordCount: len(strings.Fields(body)),
+		Preamble:  true,
+		bodyStart: bodyStart,
+	}
+}
+
+// isDocumentTitle reports whether a heading is an H1 with nothing above it
+func isDocumentTitle(lines []string, heading *markdown.Block) bool {
+	return heading.Level == 1 && joinLines(lines, 1, heading.StartLine-1) == ""
+}
+
+// topSectionTitle returns the outermost section heading among the ancestors
+func topSectionTitle(ancestors []*markdown.Block) string {
+	for _, heading := range ancestors {
+		if heading.Level >= 2 {
+			return heading.Text
+		}
+	}
+	return ""
+}
+
+// headingTitles returns the text of each heading block
+func headingTitles(headings []*markdown.Block) []string {
+	var titles []string
+	for _, heading := range headings {
+		titles = append(titles, heading.Text)
+	}
+	return titles
+}
+
+// joinLines returns the trimmed text of the 1-based inclusive line range
+func joinLines(lines []string, start, end int) string {
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	if start > end {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(lines[start-1:end], "\n"))
 }
 
 // generateContextFiles creates individual context files with descriptive names
 func (fa *FileAnalyzer) generateContextFiles() error {
 	var contextFiles []*ContextFile
 
+	// Extract key terms with every section as a document of the corpus
+	var contents []string
 	for _, section := range fa.sections {
-		// Generate descriptive filename based on content analysis
-		fileName := fa.generateDescriptiveFileName(section)
+		contents = append(contents, section.Content)
+	}
+	corpusKeyTerms := ExtractKeyTerms(contents, MaxKeyTerms)
 
-		// Extract key terms for indexing
-		keyTerms := fa.extractKeyTerms(section)
+	for i, section := range fa.sections {
+		// Score the section against the technology and function vocabularies
+		tech := classify(fa.Vocabulary.technology, section.Title, section.Content)
+		function := classify(fa.Vocabulary.function, section.Title, section.Content)
+
+		// Generate descriptive filename based on content analysis
+		fileName := fa.generateDescriptiveFileName(section, tech, function)
+		if section.Preamble {
+			fileName = PreambleFileName
+		}
+		if section.Part > 0 {
+			suffix := fmt.Sprintf("-part-%d", section.Part)
+			fileName = truncateName(strings.TrimSuffix(fileName, ".md"), MaxDescriptiveLength-len(suffix)) + suffix + ".md"
+		}
 
 		// Generate content summary
-		summary := fa.generateContentSummary(section)
+		summary := Summarize(section.Content, MaxSummaryLength)
 
-		// Estimate token count
-		tokenCount := len(section.Content) / TokenEstimationRatio
+		// Count tokens
+		tokenCount := fa.Tokenizer.Count(section.Content)
 
 		contextFile := &ContextFile{
 			FileName:   fileName,
@@ -159,21 +313,34 @@ func (fa *FileAnalyzer) generateContextFiles() error {
 			WordCount:  section.WordCount,
 			TokenCount: tokenCount,
 			Summary:    summary,
-			KeyTerms:   keyTerms,
+			KeyTerms:   corpusKeyTerms[i],
+			Confidence: classificationConfidence(tech, function),
+			Title:      section.Title,
+			StartLine:  section.StartLine,
+			EndLine:    section.EndLine,
+			Preamble:   section.Preamble,
+			Part:       section.Part,
+
+			HeadingPath:   section.HeadingPath,
+			DocumentTitle: section.Preamble && section.bodyStart > section.StartLine,
 		}
 
 		contextFiles = append(contextFiles, contextFile)
 	}
 
+	if fa.Nested {
+		fa.assignGroups(contextFiles, fa.sections)
+	}
+
+	// Make names unique within each directory so no file overwrites another
+	fa.resolveFileNameCollisions(contextFiles)
+
 	fa.contextFiles = contextFiles
 	return nil
 }
 
 // generateDescriptiveFileName creates meaningful filenames based on content analysis
-func (fa *FileAnalyzer) generateDescriptiveFileName(section *ContentSection) string {
-	content := strings.ToLower(section.Title + " " + section.Content)
-
-	// Extract domain-specific terms
+func (fa *FileAnalyzer) generateDescriptiveFileName(section *ContentSection, tech, function Classification) string {
 	var descriptors []string
 
 	// Add title-based descriptor
@@ -182,16 +349,11 @@ func (fa *FileAnalyzer) generateDescriptiveFileName(section *ContentSection) str
 		descriptors = append(descriptors, titleDesc)
 	}
 
-	// Add technology-based descriptors
-	techDesc := fa.extractTechnologyDescriptor(content)
-	if techDesc != "" {
-		descriptors = append(descriptors, techDesc)
-	}
-
-	// Add function-based descriptors
-	funcDesc := fa.extractFunctionDescriptor(content)
-	if funcDesc != "" {
-		descriptors = append(descriptors, funcDesc)
+	// Add technology and function descriptors unless the title already says it
+	for _, classification := range []Classification{tech, function} {
+		if classification.Descriptor != "" && !strings.Contains(titleDesc, classification.Descriptor) {
+			descriptors = append(descriptors, classification.Descriptor)
+		}
 	}
 
 	// Combine descriptors into filename
@@ -213,6 +375,22 @@ func (fa *FileAnalyzer) generateDescriptiveFileName(section *ContentSection) str
 	return fileName + ".md"
 }
 
+// classificationConfidence averages the confidence of the descriptors that matched
+func classificationConfidence(classifications ...Classification) float64 {
+	total := 0.0
+	matched := 0
+	for _, classification := range classifications {
+		if classification.Descriptor != "" {
+			total += classification.Confidence
+			matched++
+		}
+	}
+	if matched == 0 {
+		return 0
+	}
+	return total / float64(matched)
+}
+
 // extractTitleDescriptor extracts meaningful terms from section title
 func (fa *FileAnalyzer) extractTitleDescriptor(title string) string {
 	title = strings.ToLower(title)
@@ -242,94 +420,3 @@ func (fa *FileAnalyzer) extractTitleDescriptor(title string) string {
 
 	return strings.Join(meaningful, "-")
 }
-
-// extractTechnologyDescriptor identifies technology-specific terms
-func (fa *FileAnalyzer) extractTechnologyDescriptor(content string) string {
-	techPatterns := map[string]string{
-		"postgresql|postgres|pg": "postgresql",
-		"mongodb|mongo":          "mongodb",
-		"redis":                  "redis",
-		"kubernetes|k8s":         "kubernetes",
-		"docker":                 "docker",
-		"jwt|oauth":              "oauth",
-		"stripe|payment":         "payments",
-		"webhook":                "webhooks",
-		"graphql|gql":            "graphql",
-		"rest|api":               "rest-api",
-	}
-
-	for pattern, descriptor := range techPatterns {
-		matched, _ := regexp.MatchString(pattern, content)
-		if matched {
-			return descriptor
-		}
-	}
-
-	return ""
-}
-
-// extractFunctionDescriptor identifies functional aspects
-func (fa *FileAnalyzer) extractFunctionDescriptor(content string) string {
-	funcPatterns := map[string]string{
-		"authentication|auth|login":      "authentication",
-		"authorization|permission":       "authorization",
-		"database|schema|model":          "database",
-		"deployment|deploy|production":   "deployment",
-		"monitoring|metrics|logging":     "monitoring",
-		"security|encryption|compliance": "security",
-		"testing|test|spec":              "testing",
-		"configuration|config|setup":     "configuration",
-	}
-
-	for pattern, descriptor := range funcPatterns {
-		matched, _ := regexp.MatchString(pattern, content)
-		if matched {
-			return descriptor
-		}
-	}
-
-	return ""
-}
-
-// extractKeyTerms identifies important terms for indexing
-func (fa *FileAnalyzer) extractKeyTerms(section *ContentSection) []string {
-	content := strings.ToLower(section.Content)
-
-	// Define important term patterns
-	termPatterns := []string{
-		"api", "endpoint", "database", "schema", "authentication", "authorization",
-		"security", "deployment", "monitoring", "testing", "configuration",
-		"jwt", "oauth", "postgresql", "mongodb", "redis", "kubernetes", "docker",
-	}
-
-	var foundTerms []string
-	for _, term := range termPatterns {
-		if strings.Contains(content, term) {
-			foundTerms = append(foundTerms, term)
-		}
-	}
-
-	return foundTerms
-}
-
-// generateContentSummary creates a brief summary for indexing
-func (fa *FileAnalyzer) generateContentSummary(section *ContentSection) string {
-	content := section.Content
-
-	// Take first sentence or first 100 characters
-	sentences := strings.Split(content, ".")
-	if len(sentences) > 0 && len(sentences[0]) > 10 {
-		summary := strings.TrimSpace(sentences[0])
-		if len(summary) > 100 {
-			summary = summary[:100] + "..."
-		}
-		return summary
-	}
-
-	// Fallback to first 100 characters
-	if len(content) > 100 {
-		return content[:100] + "..."
-	}
-
-	return content
-}
@@ -0,0 +1,209 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSections(t *testing.T) {
+	content := `# Project
+
+Overview of the project and the rules everyone should follow.
+
+## Setup
+
+Install the tooling before doing anything else in this repository:
+
+` + "```bash" + `
+## install deps
+npm install
+` + "```" + `
+
+## API
+
+### Endpoints
+The endpoints are documented here with enough words to keep them.
+`
+
+	sections := buildSections(content)
+
+	var titles []string
+	for _, section := range sections {
+		titles = append(titles, section.Title)
+	}
+	if got, want := strings.Join(titles, "|"), "Project|Setup|Endpoints"; got != want {
+		t.Fatalf("buildSections() titles = %q, want %q", got, want)
+	}
+
+	preamble := sections[0]
+	if !preamble.Preamble || preamble.StartLine != 1 || preamble.EndLine != 4 {
+		t.Errorf("preamble = %+v, want lines 1-4 marked as preamble", preamble)
+	}
+
+	setup := sections[1]
+	if !strings.Contains(setup.Content, "## install deps") {
+		t.Errorf("setup section lost fenced code content: %q", setup.Content)
+	}
+	if setup.StartLine != 5 || setup.EndLine != 13 {
+		t.Errorf("setup lines = %d-%d, want 5-13", setup.StartLine, setup.EndLine)
+	}
+
+	endpoints := sections[2]
+	if strings.Join(endpoints.HeadingPath, "/") != "API" {
+		t.Errorf("endpoints HeadingPath = %v, want [API]", endpoints.HeadingPath)
+	}
+}
+
+func TestResolveFileNameCollisions(t *testing.T) {
+	fa := NewFileAnalyzer("")
+	files := []*ContextFile{
+		{FileName: "auth.md", HeadingPath: []string{"Backend"}},
+		{FileName: "auth.md", HeadingPath: []string{"Frontend"}},
+		{FileName: "frontend-auth.md"},
+		{FileName: "auth.md"},
+		{FileName: "setup.md"},
+	}
+
+	fa.resolveFileNameCollisions(files)
+
+	want := []string{"auth.md", "auth-2.md", "frontend-auth.md", "auth-3.md", "setup.md"}
+	for i, file := range files {
+		if file.FileName != want[i] {
+			t.Errorf("files[%d].FileName = %q, want %q", i, file.FileName, want[i])
+		}
+	}
+
+	if files[1].OriginalFileName != "auth.md" {
+		t.Errorf("renamed file OriginalFileName = %q, want %q", files[1].OriginalFileName, "auth.md")
+	}
+	if files[0].OriginalFileName != "" {
+		t.Errorf("first file should keep its name, got OriginalFileName = %q", files[0].OriginalFileName)
+	}
+
+	files = []*ContextFile{
+		{FileName: "auth.md", HeadingPath: []string{"Backend"}},
+		{FileName: "auth.md", HeadingPath: []string{"Frontend Apps"}},
+	}
+	fa.resolveFileNameCollisions(files)
+	if files[1].FileName != "frontend-apps-auth.md" {
+		t.Errorf("heading path qualification = %q, want %q", files[1].FileName, "frontend-apps-auth.md")
+	}
+}
+
+func TestNestedGroups(t *testing.T) {
+	content := `# Nest Demo
+
+## API
+
+The API is a REST service with JSON bodies and token authentication everywhere.
+
+### Authentication
+
+Tokens are issued by the login route and expire after one hour of inactivity.
+
+## Admin
+
+### Authentication
+
+Admins sign in with single sign-on and need a hardware key for every session.
+
+## Deployment
+
+Deploys run from GitHub Actions on every tagged release of the main branch.
+`
+	sourceFile := filepath.Join(t.TempDir(), "CLAUDE.md")
+	if err := os.WriteFile(sourceFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fa := NewFileAnalyzer(sourceFile)
+	fa.Nested = true
+	files, err := fa.AnalyzeAndGenerate(context.Background())
+	if err != nil {
+		t.Fatalf("AnalyzeAndGenerate() error = %v", err)
+	}
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path())
+	}
+
+	// Same-named subsections in different directories do not collide
+	want := []string{"api/api-rest-api-authentication.md", "api/authentication.md", "admin/authentication.md", "deployment.md"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestPreambleDocumentTitle(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantTitle string
+		wantDoc   bool
+	}{
+		{
+			name:      "leading H1",
+			content:   "# Acme Engineering Rules\n\nEvery change needs a review and passing tests before it merges.\n\n## Setup\n\nInstall Go and run make to build the binary and its assets.\n",
+			wantTitle: "Acme Engineering Rules",
+			wantDoc:   true,
+		},
+		{
+			name:      "no H1",
+			content:   "Every change needs a review and passing tests before it merges.\n\n## Setup\n\nInstall Go and run make to build the binary and its assets.\n",
+			wantTitle: DefaultPreambleTitle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceFile := filepath.Join(t.TempDir(), "CLAUDE.md")
+			if err := os.WriteFile(sourceFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			files, err := NewFileAnalyzer(sourceFile).AnalyzeAndGenerate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			preamble := files[0]
+			if !preamble.Preamble || preamble.Title != tt.wantTitle || preamble.DocumentTitle != tt.wantDoc {
+				t.Errorf("preamble = %q (document title %v), want %q (%v)", preamble.Title, preamble.DocumentTitle, tt.wantTitle, tt.wantDoc)
+			}
+		})
+	}
+}
+
+func TestPartFileNamesKeepSuffix(t *testing.T) {
+	paragraph := "Each paragraph of this section explains one more rule about the release process and its checks.\n\n"
+	content := "# Rules\n\n## Internationalization Containerization Responsibilities\n\n" + strings.Repeat(paragraph, 4)
+	sourceFile := filepath.Join(t.TempDir(), "CLAUDE.md")
+	if err := os.WriteFile(sourceFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fa := NewFileAnalyzer(sourceFile)
+	fa.MaxChapterTokens = 30
+	files, err := fa.AnalyzeAndGenerate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := 0
+	for _, file := range files {
+		if file.Preamble {
+			continue
+		}
+		parts++
+		base := strings.TrimSuffix(file.FileName, ".md")
+		if len(base) > MaxDescriptiveLength || !strings.HasSuffix(base, fmt.Sprintf("-part-%d", parts)) {
+			t.Errorf("FileName = %q, want at most %d characters ending in -part-%d", file.FileName, MaxDescriptiveLength, parts)
+		}
+	}
+	if parts < 2 {
+		t.Errorf("got %d parts, want the section split", parts)
+	}
+}
@@ -0,0 +1,212 @@
+package classifier
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/markdown"
+)
+
+// Key term extraction limits and weights
+const (
+	MaxKeyTerms        = 8   // Terms reported per chapter
+	MaxPhraseWords     = 3   // Longest candidate phrase
+	MaxIdentifierLen   = 40  // Longest inline code span treated as an identifier
+	PhraseBoost        = 1.5 // Multi-word phrases are more distinctive than single words
+	IdentifierBoost    = 2.0 // Backticked identifiers are explicit references
+	MinKeyTermMentions = 2   // Single words must appear at least this often in a chapter
+)
+
+// Regular expression patterns for term extraction
+var (
+	inlineCodePattern  = regexp.MustCompile("`([^`\n]+)`")
+	linkTargetPattern  = regexp.MustCompile(`\]\([^)]*\)`)
+	wordPattern        = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_.+-]*[A-Za-z0-9+]|[A-Za-z]`)
+	phraseBreakPattern = regexp.MustCompile(`[.,;:!?()\[\]{}"|<>*=/\\]+|\s-\s|\n`)
+)
+
+// stopWords are excluded from terms and break candidate phrases (RAKE-style)
+var stopWords = toSet(`a about above after again against all also always am an and any are as at be
+because been before being below between both but by can could did do does doing done down during each
+either else etc every few for from further get gets got had has have having here how however if in into is
+it its itself just least less like make makes many may might more most much must need needs never new no
+nor not now of off often on once one only or other our out over own per please rather really same see
+should since so some still such than that the their them then there these they this those through thus
+to too under until up upon use used uses using very via was way we well were what when where whether which
+while who why will with within without would yes yet you your e.g i.e ensure instead first second next
+make sure note example examples following file files section chapter`)
+
+// termStats holds per-document term counts for a corpus
+type termStats struct {
+	counts []map[string]float64 // Weighted term counts per document
+	totals []float64            // Total weighted terms per document
+	df     map[string]int       // Number of documents containing each term
+}
+
+// ExtractKeyTerms treats each content string as a document in a corpus and
+// returns the most distinctive terms of each one by TF-IDF. Candidates are
+// single words, RAKE-style phrases (runs of non-stop words) and identifiers in
+// backticks. At most limit terms are returned per document.
+func ExtractKeyTerms(contents []string, limit int) [][]string {
+	stats := &termStats{df: make(map[string]int)}
+	for _, content := range contents {
+		counts := countTerms(content)
+		total := 0.0
+		for term, count := range counts {
+			total += count
+			stats.df[term]++
+		}
+		stats.counts = append(stats.counts, counts)
+		stats.totals = append(stats.totals, total)
+	}
+
+	results := make([][]string, len(contents))
+	for i := range contents {
+		results[i] = stats.topTerms(i, limit)
+	}
+	return results
+}
+
+// topTerms ranks a document's terms by boosted TF-IDF with deterministic tie-breaks
+func (s *termStats) topTerms(doc, limit int) []string {
+	type scored struct {
+		term  string
+		score float64
+	}
+
+	documents := float64(len(s.counts))
+	var candidates []scored
+	for term, count := range s.counts[doc] {
+		if !strings.Contains(term, " ") && !isIdentifier(term) && count < MinKeyTermMentions {
+			continue
+		}
+		tf := count / s.totals[doc]
+		idf := math.Log((documents+1)/(float64(s.df[term])+1)) + 1
+		candidates = append(candidates, scored{term: term, score: tf * idf})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].term < candidates[j].term
+	})
+
+	// A single word is dropped once a chosen phrase contains it, whichever
+	// ranked higher, so "user accounts" is never listed next to "user"
+	var terms []string
+	for _, candidate := range candidates {
+		if len(terms) >= limit {
+			break
+		}
+		if coveredByPhrase(candidate.term, terms) {
+			continue
+		}
+		if strings.Contains(candidate.term, " ") {
+			terms = dropCoveredWords(terms, candidate.term)
+		}
+		terms = append(terms, candidate.term)
+	}
+	return terms
+}
+
+// dropCoveredWords removes the single words of phrase from terms
+func dropCoveredWords(terms []string, phrase string) []string {
+	kept := terms[:0]
+	for _, term := range terms {
+		if !coveredByPhrase(term, []string{phrase}) {
+			kept = append(kept, term)
+		}
+	}
+	return kept
+}
+
+// countTerms extracts weighted candidate terms from the prose of a chapter.
+// Code blocks are skipped; inline code spans become identifier terms.
+func countTerms(content string) map[string]float64 {
+	counts := make(map[string]float64)
+	prose := proseText(content)
+
+	for _, match := range inlineCodePattern.FindAllStringSubmatch(prose, -1) {
+		identifier := strings.TrimSpace(match[1])
+		if identifier != "" && len(identifier) <= MaxIdentifierLen {
+			counts["`"+identifier+"`"] += IdentifierBoost
+		}
+	}
+	prose = inlineCodePattern.ReplaceAllString(prose, "\n")
+	prose = linkTargetPattern.ReplaceAllString(prose, "]")
+
+	for _, fragment := range phraseBreakPattern.Split(prose, -1) {
+		var run []string
+		flush := func() {
+			if len(run) >= 2 && len(run) <= MaxPhraseWords {
+				counts[strings.Join(run, " ")] += PhraseBoost
+			}
+			run = run[:0]
+		}
+
+		for _, word := range wordPattern.FindAllString(fragment, -1) {
+			word = strings.ToLower(strings.TrimRight(word, ".-"))
+			if len(word) < 3 || stopWords[word] {
+				flush()
+				continue
+			}
+			counts[word]++
+			run = append(run, word)
+		}
+		flush()
+	}
+
+	return counts
+}
+
+// proseText returns content with fenced and indented code blocks removed
+func proseText(content string) string {
+	lines := markdown.SplitLines(content)
+	skip := make([]bool, len(lines)+1)
+	for _, block := range markdown.Parse(content) {
+		if block.Kind == markdown.BlockFencedCode || block.Kind == markdown.BlockIndentedCode || block.Kind == markdown.BlockHTML {
+			for n := block.StartLine; n <= block.EndLine; n++ {
+				skip[n] = true
+			}
+		}
+	}
+
+	var prose strings.Builder
+	for i, line := range lines {
+		if !skip[i+1] {
+			prose.WriteString(line)
+		}
+		prose.WriteString("\n")
+	}
+	return prose.String()
+}
+
+// coveredByPhrase reports whether a single word appears in an already selected phrase
+func coveredByPhrase(term string, selected []string) bool {
+	if strings.Contains(term, " ") || isIdentifier(term) {
+		return false
+	}
+	for _, phrase := range selected {
+		for _, word := range strings.Fields(phrase) {
+			if word == term {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isIdentifier(term string) bool {
+	return strings.HasPrefix(term, "`")
+}
+
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,79 @@
+package classifier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractKeyTerms(t *testing.T) {
+	contents := []string{
+		"Tokens are signed with RS256. Refresh tokens rotate daily. Call `issueToken` to mint tokens for the auth service.",
+		"Migrations run before deploys. Every migration is reviewed. The `schema_version` table tracks each migration.\n\n```sql\nSELECT tokens FROM secret_table;\n```",
+		"Tokens and migrations are both mentioned here, with tokens twice and migrations twice.",
+	}
+
+	terms := ExtractKeyTerms(contents, 5)
+	if len(terms) != len(contents) {
+		t.Fatalf("ExtractKeyTerms() returned %d results, want %d", len(terms), len(contents))
+	}
+
+	if !containsTerm(terms[0], "`issueToken`") {
+		t.Errorf("backticked identifier missing from %v", terms[0])
+	}
+	// A frequent word may be listed on its own or within a phrase
+	if !containsTerm(terms[0], "tokens") && !coveredByPhrase("tokens", terms[0]) {
+		t.Errorf("frequent word missing from %v", terms[0])
+	}
+	if !containsTerm(terms[1], "`schema_version`") || !containsTerm(terms[1], "migration") {
+		t.Errorf("expected identifier and frequent word in %v", terms[1])
+	}
+	for _, term := range terms[1] {
+		if strings.Contains(term, "secret_table") || term == "select" {
+			t.Errorf("terms from fenced code leaked into %v", terms[1])
+		}
+	}
+	for _, doc := range terms {
+		for _, term := range doc {
+			if stopWords[term] {
+				t.Errorf("stop word %q reported as key term", term)
+			}
+		}
+		if len(doc) > 5 {
+			t.Errorf("ExtractKeyTerms() returned %d terms, limit is 5", len(doc))
+		}
+	}
+
+	// The same corpus must always produce the same terms in the same order
+	for i := 0; i < 20; i++ {
+		if again := ExtractKeyTerms(contents, 5); !reflect.DeepEqual(again, terms) {
+			t.Fatalf("ExtractKeyTerms() is not deterministic: %v vs %v", again, terms)
+		}
+	}
+}
+
+func containsTerm(terms []string, want string) bool {
+	for _, term := range terms {
+		if term == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestKeyTermsDropWordsOfPhrases(t *testing.T) {
+	contents := []string{
+		"User accounts are created on signup. User accounts can be disabled. Each user has accounts in several regions, and the user list pages through accounts.",
+		"Deploys run from CI on every tagged release of the main branch.",
+	}
+
+	terms := ExtractKeyTerms(contents, 5)
+	if !containsTerm(terms[0], "user accounts") {
+		t.Fatalf("phrase missing from %v", terms[0])
+	}
+	for _, word := range []string{"user", "accounts"} {
+		if containsTerm(terms[0], word) {
+			t.Errorf("word %q listed next to the phrase that contains it: %v", word, terms[0])
+		}
+	}
+}
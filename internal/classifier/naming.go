@@ -0,0 +1,123 @@
+package classifier
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/validation"
+)
+
+// resolveFileNameCollisions makes every filename unique within its directory.
+// Files are processed in source order: the first file keeps its name, later
+// duplicates are qualified with their heading path and, if that is still
+// taken, get an ordinal suffix. Renamed files record their original name.
+func (fa *FileAnalyzer) resolveFileNameCollisions(files []*ContextFile) {
+	// Every generated name is reserved so a renamed file never takes a name
+	// that a later file arrives with
+	reserved := make(map[string]bool)
+	for _, file := range files {
+		reserved[strings.ToLower(file.Path())] = true
+	}
+
+	assigned := make(map[string]bool)
+	for _, file := range files {
+		if assigned[strings.ToLower(file.Path())] {
+			group := file.Group
+			isFree := func(name string) bool {
+				key := strings.ToLower(path.Join(group, name))
+				return !reserved[key] && !assigned[key]
+			}
+			file.OriginalFileName = file.FileName
+			file.FileName = fa.uniqueFileName(file, isFree)
+		}
+		assigned[strings.ToLower(file.Path())] = true
+	}
+}
+
+// assignGroups places the chapters of each top-level section that has
+// subsections in a subdirectory named after that section. Top-level sections
+// without subsections and the preamble stay at the top of the context dir.
+func (fa *FileAnalyzer) assignGroups(files []*ContextFile, sections []*ContentSection) {
+	type group struct {
+		title  string
+		files  []*ContextFile
+		nested bool // True when the group holds at least one subsection
+	}
+
+	var groups []*group
+	var current *group
+	for i, section := range sections {
+		if section.Preamble {
+			continue
+		}
+		if section.TopSection == "" {
+			current = &group{title: section.Title}
+			groups = append(groups, current)
+		} else if current == nil || current.title != section.TopSection {
+			// The top-level section itself was dropped or merged away
+			current = &group{title: section.TopSection}
+			groups = append(groups, current)
+		}
+		current.files = append(current.files, files[i])
+		current.nested = current.nested || section.TopSection != ""
+	}
+
+	used := make(map[string]bool)
+	for _, g := range groups {
+		if !g.nested {
+			continue
+		}
+
+		base := validation.SanitizeFileName(fa.extractTitleDescriptor(g.title))
+		if base == "" {
+			base = "section"
+		}
+		base = truncateName(base, MaxDescriptiveLength)
+		dir := base
+		for ordinal := 2; used[dir]; ordinal++ {
+			dir = fmt.Sprintf("%s-%d", base, ordinal)
+		}
+		used[dir] = true
+
+		for _, file := range g.files {
+			file.Group = dir
+		}
+	}
+}
+
+// uniqueFileName finds a free name for a colliding file, preferring heading
+// path segments over numeric suffixes
+func (fa *FileAnalyzer) uniqueFileName(file *ContextFile, isFree func(string) bool) string {
+	base := strings.TrimSuffix(file.FileName, ".md")
+
+	// Qualify with enclosing headings, nearest first
+	qualified := base
+	for i := len(file.HeadingPath) - 1; i >= 0; i-- {
+		segment := fa.extractTitleDescriptor(file.HeadingPath[i])
+		if segment == "" {
+			continue
+		}
+		qualified = truncateName(validation.SanitizeFileName(segment+"-"+qualified), MaxDescriptiveLength)
+		if isFree(qualified + ".md") {
+			return qualified + ".md"
+		}
+	}
+
+	// Fall back to an ordinal suffix on the original name
+	for ordinal := 2; ; ordinal++ {
+		suffix := fmt.Sprintf("-%d", ordinal)
+		candidate := truncateName(base, MaxDescriptiveLength-len(suffix)) + suffix + ".md"
+		if isFree(candidate) {
+			return candidate
+		}
+	}
+}
+
+// truncateName limits a name to the given length without leaving a trailing dash
+func truncateName(name string, limit int) string {
+	if len(name) > limit {
+		name = strings.TrimRight(name[:limit], "-")
+	}
+	return name
+}
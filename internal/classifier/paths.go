@@ -0,0 +1,47 @@
+package classifier
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pathPattern matches runs of characters that can make up a relative file path
+var pathPattern = regexp.MustCompile(`[\w.\-/]+`)
+
+// fileExtensionPattern matches a file name ending in an extension, such as main.go
+var fileExtensionPattern = regexp.MustCompile(`[\w\-]\.[A-Za-z][A-Za-z0-9]{0,7}$`)
+
+// MentionedPaths returns the relative file and directory paths a chapter
+// mentions, in order of first mention. A candidate contains a slash or ends
+// in a file extension; whether it exists is left to the caller. Absolute
+// paths, paths leaving their directory and URLs are skipped.
+func MentionedPaths(content string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		// The host and path of a URL match as //host/path and are skipped as absolute
+		for _, match := range pathPattern.FindAllString(line, -1) {
+			candidate := strings.TrimRight(match, ".")
+			candidate = strings.TrimPrefix(candidate, "./")
+			if !isRelativePath(candidate) || seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			paths = append(paths, candidate)
+		}
+	}
+	return paths
+}
+
+// isRelativePath reports whether a candidate looks like a path inside the project
+func isRelativePath(candidate string) bool {
+	if candidate == "" || strings.HasPrefix(candidate, "/") {
+		return false
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(candidate, "/"), "/") {
+		if part == "" || part == ".." {
+			return false
+		}
+	}
+	return strings.Contains(candidate, "/") || fileExtensionPattern.MatchString(candidate)
+}
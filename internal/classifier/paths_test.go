@@ -0,0 +1,48 @@
+package classifier
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMentionedPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "code spans and prose",
+			content: "Sessions live in `internal/auth/session.go`. See cmd/login.go and main.go.",
+			want:    []string{"internal/auth/session.go", "cmd/login.go", "main.go"},
+		},
+		{
+			name:    "directories and relative prefix",
+			content: "Handlers are in ./api/handlers/ and api/routes.",
+			want:    []string{"api/handlers/", "api/routes"},
+		},
+		{
+			name:    "duplicates keep first mention",
+			content: "Edit config.yaml.\nThen reload config.yaml",
+			want:    []string{"config.yaml"},
+		},
+		{
+			name:    "urls, absolute paths and parent directories",
+			content: "Read https://example.com/docs/index.html, /etc/hosts and ../shared/util.go",
+			want:    nil,
+		},
+		{
+			name:    "plain words",
+			content: "Use tokens and sessions. Version 2 is current.",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MentionedPaths(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MentionedPaths() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package classifier
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Weights applied to term hits depending on where and how they occur
+const (
+	HeadingWeight = 3.0 // A hit in the section heading
+	BodyWeight    = 1.0 // A hit in the section body
+	SynonymWeight = 0.5 // Multiplier for hits on a term's synonyms
+)
+
+// Classification is the outcome of scoring a section against a term table
+type Classification struct {
+	Descriptor string  // Winning descriptor, empty when nothing matched
+	Score      float64 // Weighted hit count of the winning descriptor
+	Confidence float64 // Share of all matched score held by the winner (0-1)
+}
+
+// termMatcher is a Term with its patterns compiled into word-boundary regular expressions
+type termMatcher struct {
+	descriptor string
+	weight     float64
+	patterns   *regexp.Regexp
+	synonyms   *regexp.Regexp
+}
+
+// compileTerms builds word-boundary matchers so that "pg" does not match "page"
+// and "api" does not match "rapid"
+func compileTerms(terms []Term) ([]termMatcher, error) {
+	matchers := make([]termMatcher, 0, len(terms))
+	for _, term := range terms {
+		patterns, err := compileAlternatives(term.Patterns)
+		if err != nil {
+			return nil, fmt.Errorf("term %s: %w", term.Descriptor, err)
+		}
+		synonyms, err := compileAlternatives(term.Synonyms)
+		if err != nil {
+			return nil, fmt.Errorf("term %s: %w", term.Descriptor, err)
+		}
+
+		weight := term.Weight
+		if weight == 0 {
+			weight = 1
+		}
+
+		matchers = append(matchers, termMatcher{
+			descriptor: term.Descriptor,
+			weight:     weight,
+			patterns:   patterns,
+			synonyms:   synonyms,
+		})
+	}
+	return matchers, nil
+}
+
+// compileAlternatives joins words or phrases into one case-insensitive expression,
+// returning nil for an empty list. Phrase words may be separated by spaces or dashes.
+func compileAlternatives(phrases []string) (*regexp.Regexp, error) {
+	var alternatives []string
+	for _, phrase := range phrases {
+		words := strings.Fields(strings.ToLower(phrase))
+		if len(words) == 0 {
+			continue
+		}
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		alternatives = append(alternatives, strings.Join(words, `[\s-]+`))
+	}
+	if len(alternatives) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(`(?i)\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+}
+
+// countHits counts non-overlapping matches, treating a nil expression as no match
+func countHits(pattern *regexp.Regexp, text string) int {
+	if pattern == nil {
+		return 0
+	}
+	return len(pattern.FindAllStringIndex(text, -1))
+}
+
+// classify scores every descriptor by weighted term frequency and returns the
+// best one. Scores for a descriptor defined in several packs are summed. Ties
+// go to the descriptor with more heading hits, then to the one listed first,
+// so the result never depends on map iteration order.
+func classify(matchers []termMatcher, heading, body string) Classification {
+	type tally struct {
+		descriptor  string
+		score       float64
+		headingHits int
+	}
+
+	var tallies []*tally
+	byDescriptor := make(map[string]*tally)
+	total := 0.0
+
+	for _, matcher := range matchers {
+		headingHits := countHits(matcher.patterns, heading)
+		headingScore := float64(headingHits) + float64(countHits(matcher.synonyms, heading))*SynonymWeight
+		bodyScore := float64(countHits(matcher.patterns, body)) + float64(countHits(matcher.synonyms, body))*SynonymWeight
+		score := (headingScore*HeadingWeight + bodyScore*BodyWeight) * matcher.weight
+		if score == 0 {
+			continue
+		}
+		total += score
+
+		t, exists := byDescriptor[matcher.descriptor]
+		if !exists {
+			t = &tally{descriptor: matcher.descriptor}
+			byDescriptor[matcher.descriptor] = t
+			tallies = append(tallies, t)
+		}
+		t.score += score
+		t.headingHits += headingHits
+	}
+
+	var best *tally
+	for _, t := range tallies {
+		if best == nil || t.score > best.score || (t.score == best.score && t.headingHits > best.headingHits) {
+			best = t
+		}
+	}
+
+	if best == nil {
+		return Classification{}
+	}
+	return Classification{
+		Descriptor: best.descriptor,
+		Score:      best.score,
+		Confidence: best.score / total,
+	}
+}
@@ -0,0 +1,83 @@
+package classifier
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		matchers []termMatcher
+		heading  string
+		body     string
+		want     string
+	}{
+		{
+			name:     "pg does not match page",
+			matchers: DefaultVocabulary().technology,
+			heading:  "Layout",
+			body:     "Every page renders a header",
+			want:     "",
+		},
+		{
+			name:     "api does not match rapid",
+			matchers: DefaultVocabulary().technology,
+			heading:  "Iteration",
+			body:     "We value rapid feedback",
+			want:     "",
+		},
+		{
+			name:     "rest does not match interest",
+			matchers: DefaultVocabulary().technology,
+			heading:  "Notes",
+			body:     "Compound interest is computed nightly",
+			want:     "",
+		},
+		{
+			name:     "heading hits outweigh body hits",
+			matchers: DefaultVocabulary().technology,
+			heading:  "Redis caching",
+			body:     "Sessions live in postgres. Reports read from postgres replicas.",
+			want:     "redis",
+		},
+		{
+			name:     "body frequency decides without heading hits",
+			matchers: DefaultVocabulary().function,
+			heading:  "Notes",
+			body:     "Run tests before every deploy. Tests must pass. Write a test for each bug.",
+			want:     "testing",
+		},
+		{
+			name:     "ties resolve by table order",
+			matchers: DefaultVocabulary().technology,
+			heading:  "Stores",
+			body:     "We use redis and mongo",
+			want:     "mongodb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classify(tt.matchers, tt.heading, tt.body)
+			if got.Descriptor != tt.want {
+				t.Errorf("classify() = %q, want %q", got.Descriptor, tt.want)
+			}
+			if tt.want == "" && got.Confidence != 0 {
+				t.Errorf("classify() confidence = %v for no match, want 0", got.Confidence)
+			}
+		})
+	}
+}
+
+func TestClassifyIsDeterministic(t *testing.T) {
+	heading := "Deployment"
+	body := "Deploy the api with docker and kubernetes, then check postgres metrics and auth logs."
+
+	first := classify(DefaultVocabulary().technology, heading, body)
+	for i := 0; i < 50; i++ {
+		if got := classify(DefaultVocabulary().technology, heading, body); got != first {
+			t.Fatalf("classify() run %d = %+v, want %+v", i, got, first)
+		}
+	}
+	if first.Confidence <= 0 || first.Confidence > 1 {
+		t.Errorf("classify() confidence = %v, want within (0, 1]", first.Confidence)
+	}
+}
@@ -0,0 +1,272 @@
+package classifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/markdown"
+)
+
+// Sizing actions
+const (
+	SizingSplit = "split"
+	SizingMerge = "merge"
+	SizingKeep  = "keep"
+)
+
+// SizingDecision records a split or merge made to fit the chapter token budget
+type SizingDecision struct {
+	Action string // SizingSplit, SizingMerge or SizingKeep
+	Title  string // Section the decision applies to
+	Tokens int    // Tokens of the section before the decision
+	Parts  int    // Chapters produced by a split
+	Target string // Section a merge went into
+	Reason string // Why, in words
+}
+
+// String describes the decision for reports
+func (d SizingDecision) String() string {
+	switch d.Action {
+	case SizingSplit:
+		return fmt.Sprintf("Split %q (%d tokens) into %d chapters %s", d.Title, d.Tokens, d.Parts, d.Reason)
+	case SizingMerge:
+		return fmt.Sprintf("Merged %q (%d tokens) into %s %q", d.Title, d.Tokens, d.Reason, d.Target)
+	default:
+		return fmt.Sprintf("Kept %q (%d tokens) whole: %s", d.Title, d.Tokens, d.Reason)
+	}
+}
+
+// SizingDecisions returns the splits and merges made by the last analysis
+func (fa *FileAnalyzer) SizingDecisions() []SizingDecision {
+	return fa.decisions
+}
+
+// buildSizedSections builds sections that fit the chapter token budget. With a
+// maximum, each top-level section is kept whole with its subsections unless it
+// is too large; then it is split at its sub-headings, or at paragraph
+// boundaries when it has none. With a minimum, sections that are too small are
+// merged into the previous section, usually their parent, or else the next one.
+func (fa *FileAnalyzer) buildSizedSections(content string) []*ContentSection {
+	lines := markdown.SplitLines(content)
+	blocks := markdown.Parse(content)
+	headings := markdown.Headings(blocks)
+	fa.decisions = nil
+
+	sections := splitSections(lines, headings)
+	if fa.MaxChapterTokens > 0 {
+		sections = fa.fitToBudget(lines, blocks, sections)
+	}
+	if fa.MinChapterTokens > 0 {
+		sections = fa.mergeSmallSections(lines, sections)
+	} else {
+		sections = dropTinySections(sections)
+	}
+
+	if preamble := buildPreamble(lines, headings); preamble != nil {
+		sections = append([]*ContentSection{preamble}, sections...)
+	}
+	return sections
+}
+
+// fitToBudget keeps each section whole with its descendants when the whole
+// fits in MaxChapterTokens and otherwise splits it
+func (fa *FileAnalyzer) fitToBudget(lines []string, blocks []*markdown.Block, sections []*ContentSection) []*ContentSection {
+	var fitted []*ContentSection
+	for i := 0; i < len(sections); {
+		root := sections[i]
+		end := i + 1
+		for end < len(sections) && isDescendant(sections[end], root) {
+			end++
+		}
+
+		whole := withLines(lines, root, root.StartLine, sections[end-1].EndLine, root.bodyStart)
+		tokens := fa.Tokenizer.Count(whole.Content)
+		switch {
+		case tokens <= fa.MaxChapterTokens:
+			fitted = append(fitted, whole)
+		case end > i+1:
+			// Record the split before the decisions made for the subsections
+			fa.decisions = append(fa.decisions, SizingDecision{
+				Action: SizingSplit,
+				Title:  root.Title,
+				Tokens: tokens,
+				Reason: "at its sub-headings",
+			})
+			decision := len(fa.decisions) - 1
+			own := fa.splitParagraphs(lines, blocks, root)
+			children := fa.fitToBudget(lines, blocks, sections[i+1:end])
+			fa.decisions[decision].Parts = len(own) + len(children)
+			fitted = append(append(fitted, own...), children...)
+		default:
+			fitted = append(fitted, fa.splitParagraphs(lines, blocks, root)...)
+		}
+		i = end
+	}
+	return fitted
+}
+
+// splitParagraphs splits a section without subsections into parts at block
+// boundaries so that each part fits in MaxChapterTokens where possible. Code
+// blocks, lists and tables are never cut.
+func (fa *FileAnalyzer) splitParagraphs(lines []string, blocks []*markdown.Block, section *ContentSection) []*ContentSection {
+	tokens := fa.Tokenizer.Count(section.Content)
+	if tokens <= fa.MaxChapterTokens {
+		return []*ContentSection{section}
+	}
+
+	// Greedily pack body blocks into parts; a new part starts at a block
+	// boundary once the current part would exceed the budget
+	starts := []int{section.StartLine}
+	partStart := section.bodyStart
+	for _, block := range blocks {
+		if block.StartLine < section.bodyStart || block.EndLine > section.EndLine {
+			continue
+		}
+		if block.StartLine > partStart && fa.Tokenizer.Count(joinLines(lines, partStart, block.EndLine)) > fa.MaxChapterTokens {
+			starts = append(starts, block.StartLine)
+			partStart = block.StartLine
+		}
+	}
+
+	if len(starts) == 1 {
+		fa.decisions = append(fa.decisions, SizingDecision{
+			Action: SizingKeep,
+			Title:  section.Title,
+			Tokens: tokens,
+			Reason: "a single block cannot be split",
+		})
+		return []*ContentSection{section}
+	}
+
+	var parts []*ContentSection
+	for n, start := range starts {
+		end := section.EndLine
+		if n+1 < len(starts) {
+			end = starts[n+1] - 1
+		}
+		bodyStart := start
+		if n == 0 {
+			bodyStart = section.bodyStart
+		}
+		part := withLines(lines, section, start, end, bodyStart)
+		part.Part = n + 1
+		parts = append(parts, part)
+	}
+
+	fa.decisions = append(fa.decisions, SizingDecision{
+		Action: SizingSplit,
+		Title:  section.Title,
+		Tokens: tokens,
+		Parts:  len(parts),
+		Reason: "at paragraph boundaries",
+	})
+	return parts
+}
+
+// mergeSmallSections folds sections below MinChapterTokens into the previous
+// section, or into the next one when the previous is missing or too full. A
+// heading whose subsections follow it is merged forward into its first child,
+// even when its previous sibling has room.
+func (fa *FileAnalyzer) mergeSmallSections(lines []string, sections []*ContentSection) []*ContentSection {
+	var merged []*ContentSection
+	var carry *ContentSection // Small section waiting to be merged into the next one
+
+	for i, section := range sections {
+		if carry != nil {
+			if fa.fitsBudget(lines, carry.StartLine, section.EndLine) {
+				relation := "next section"
+				if isDescendant(section, carry) {
+					relation = "first subsection"
+				}
+				fa.recordMerge(carry, section, relation)
+				section = withLines(lines, section, carry.StartLine, section.EndLine, carry.StartLine)
+			} else {
+				merged = append(merged, carry)
+			}
+			carry = nil
+		}
+
+		if fa.Tokenizer.Count(section.Content) >= fa.MinChapterTokens {
+			merged = append(merged, section)
+			continue
+		}
+
+		var previous, next *ContentSection
+		if len(merged) > 0 {
+			previous = merged[len(merged)-1]
+		}
+		if i+1 < len(sections) {
+			next = sections[i+1]
+		}
+
+		// A parent goes into its first child, and a section unrelated to the
+		// previous one into a related next one; otherwise prefer the previous
+		if next != nil && (isDescendant(next, section) || previous == nil || isRelated(next, section) && !isRelated(section, previous)) &&
+			fa.fitsBudget(lines, section.StartLine, next.EndLine) {
+			carry = section
+			continue
+		}
+		if previous != nil && fa.fitsBudget(lines, previous.bodyStart, section.EndLine) {
+			relation := "previous section"
+			if isDescendant(section, previous) {
+				relation = "parent"
+			}
+			fa.recordMerge(section, previous, relation)
+			merged[len(merged)-1] = withLines(lines, previous, previous.StartLine, section.EndLine, previous.bodyStart)
+			continue
+		}
+		carry = section
+	}
+
+	// A small last section with nowhere to go stays as it is
+	if carry != nil {
+		merged = append(merged, carry)
+	}
+	return merged
+}
+
+// fitsBudget reports whether a line range stays within MaxChapterTokens
+func (fa *FileAnalyzer) fitsBudget(lines []string, start, end int) bool {
+	return fa.MaxChapterTokens <= 0 || fa.Tokenizer.Count(joinLines(lines, start, end)) <= fa.MaxChapterTokens
+}
+
+func (fa *FileAnalyzer) recordMerge(section, target *ContentSection, relation string) {
+	fa.decisions = append(fa.decisions, SizingDecision{
+		Action: SizingMerge,
+		Title:  section.Title,
+		Tokens: fa.Tokenizer.Count(section.Content),
+		Target: target.Title,
+		Reason: relation,
+	})
+}
+
+// withLines returns a copy of a section covering a new line range. Content
+// runs from bodyStart, so a heading before it is carried by the file title.
+func withLines(lines []string, section *ContentSection, start, end, bodyStart int) *ContentSection {
+	resized := *section
+	resized.StartLine = start
+	resized.EndLine = end
+	resized.bodyStart = bodyStart
+	resized.Content = joinLines(lines, bodyStart, end)
+	resized.WordCount = len(strings.Fields(resized.Content))
+	return &resized
+}
+
+// isRelated reports whether a section is nested under another or is its sibling
+func isRelated(section, other *ContentSection) bool {
+	return isDescendant(section, other) || strings.Join(section.HeadingPath, "\x00") == strings.Join(other.HeadingPath, "\x00")
+}
+
+// isDescendant reports whether a section is nested under another by heading path
+func isDescendant(section, ancestor *ContentSection) bool {
+	depth := len(ancestor.HeadingPath)
+	if len(section.HeadingPath) <= depth || section.HeadingPath[depth] != ancestor.Title {
+		return false
+	}
+	for i, title := range ancestor.HeadingPath {
+		if section.HeadingPath[i] != title {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package classifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const sizingDocument = `# Sizing Demo
+
+## Architecture
+
+### Layers
+
+The service is split into handlers, services and repositories. Handlers parse requests and call services.
+
+Services hold the business rules and never talk to HTTP directly. Repositories wrap the database access.
+
+Each layer has its own package and its own tests, and layers only depend on the layer below them.
+
+### Notes
+
+Keep it simple.
+
+## Tiny
+
+One line.
+
+## Deployment
+
+Deploys run from GitHub Actions on every tagged release of the main branch.
+
+Staging deploys happen automatically after merges, production needs a manual approval step.
+
+Rollbacks use the previous release tag and take about five minutes to complete end to end.
+`
+
+func TestBuildSizedSections(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		min       int
+		want      []string // Title/part:start-end of each section
+		decisions int
+	}{
+		{
+			name: "whole top-level sections within budget",
+			max:  500,
+			want: []string{"Architecture/0:3-16", "Deployment/0:21-27"},
+		},
+		{
+			name:      "tiny sections merge into parent or neighbor",
+			min:       15,
+			want:      []string{"Layers/0:3-16", "Deployment/0:17-27"},
+			decisions: 3,
+		},
+		{
+			name:      "oversized sections split at sub-headings then paragraphs",
+			max:       60,
+			min:       15,
+			want:      []string{"Layers/1:3-10", "Layers/2:11-16", "Deployment/1:17-26", "Deployment/2:27-27"},
+			decisions: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa := NewFileAnalyzer("")
+			fa.MaxChapterTokens = tt.max
+			fa.MinChapterTokens = tt.min
+
+			var got []string
+			for _, section := range fa.buildSizedSections(sizingDocument) {
+				got = append(got, fmt.Sprintf("%s/%d:%d-%d", section.Title, section.Part, section.StartLine, section.EndLine))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("sections = %v, want %v", got, tt.want)
+			}
+			if len(fa.SizingDecisions()) != tt.decisions {
+				t.Errorf("decisions = %v, want %d", fa.SizingDecisions(), tt.decisions)
+			}
+		})
+	}
+}
+
+func TestMergeParentIntoFirstChild(t *testing.T) {
+	document := `# Service
+
+## Installation
+
+Install the binary with go install and put it on your PATH before running it for the first time on a new machine.
+
+## API Endpoints
+
+All endpoints return JSON.
+
+### GET /users
+
+Lists users with pagination through the page and limit query parameters, newest first.
+
+### POST /users
+
+Creates a user from a JSON body with name and email fields and returns its id.
+
+### DELETE /users/{id}
+
+Deletes a user by id and returns no content when the user existed before.
+`
+	fa := NewFileAnalyzer("")
+	fa.MaxChapterTokens = 60
+	fa.MinChapterTokens = 25
+
+	// The intro of API Endpoints has room in Installation but belongs with its first child
+	var got []string
+	for _, section := range fa.buildSizedSections(document) {
+		got = append(got, fmt.Sprintf("%s:%d-%d", section.Title, section.StartLine, section.EndLine))
+	}
+	want := []string{"Installation:3-6", "GET /users:7-14", "DELETE /users/{id}:15-21"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("sections = %v, want %v", got, want)
+	}
+
+	var merge *SizingDecision
+	for i, decision := range fa.SizingDecisions() {
+		if decision.Action == SizingMerge && decision.Title == "API Endpoints" {
+			merge = &fa.SizingDecisions()[i]
+		}
+	}
+	if merge == nil || merge.Target != "GET /users" || merge.Reason != "first subsection" {
+		t.Errorf("merge of API Endpoints = %+v, want into first subsection GET /users", merge)
+	}
+}
+
+func TestSizedSectionsKeepContent(t *testing.T) {
+	fa := NewFileAnalyzer("")
+	fa.MaxChapterTokens = 60
+	fa.MinChapterTokens = 15
+	sections := fa.buildSizedSections(sizingDocument)
+
+	// Every body line must end up in exactly one section
+	var joined strings.Builder
+	for _, section := range sections {
+		joined.WriteString(section.Content + "\n")
+	}
+	for _, line := range []string{"## Architecture", "Keep it simple.", "## Tiny", "One line.", "Rollbacks use"} {
+		if strings.Count(joined.String(), line) != 1 {
+			t.Errorf("line %q appears %d times in sized sections", line, strings.Count(joined.String(), line))
+		}
+	}
+
+	// The document has no preamble, so only its H1 title is left out
+	report := CheckCoverage(sizingDocument, sectionsAsFiles(sections))
+	if len(report.Uncovered) != 1 || report.Uncovered[0] != (LineRange{Start: 1, End: 1}) {
+		t.Errorf("sized sections lose lines %v, want only the title on line 1", report.Uncovered)
+	}
+}
+
+func sectionsAsFiles(sections []*ContentSection) []*ContextFile {
+	var files []*ContextFile
+	for _, section := range sections {
+		files = append(files, &ContextFile{StartLine: section.StartLine, EndLine: section.EndLine})
+	}
+	return files
+}
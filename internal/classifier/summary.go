@@ -0,0 +1,246 @@
+package classifier
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/angelcodes95/contindex/internal/markdown"
+	"github.com/angelcodes95/contindex/internal/tokens"
+)
+
+// Summarizer settings
+const (
+	MaxSummaryLength   = 100  // Maximum summary length in runes
+	MinSentenceWords   = 3    // Shorter sentences are only used when nothing else is available
+	TextRankDamping    = 0.85 // PageRank damping factor
+	TextRankIterations = 30   // Power iterations for sentence ranking
+)
+
+// Regular expression patterns for Markdown stripping
+var (
+	imagePattern       = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern        = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	refLinkPattern     = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+	autolinkPattern    = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	htmlTagPattern     = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	strongPattern      = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	emphasisPattern    = regexp.MustCompile(`(^|[^\w*])[*_](\S(?:[^*_]*?\S)?)[*_]($|[^\w*])`)
+	strikePattern      = regexp.MustCompile(`~~(.+?)~~`)
+	listMarkerPattern  = regexp.MustCompile(`^\s*(?:[-+*]|\d{1,9}[.)])\s+(?:\[[ xX]\]\s+)?`)
+	headingMarkPattern = regexp.MustCompile(`^\s{0,3}#{1,6}\s+|\s+#+\s*$`)
+	quoteMarkPattern   = regexp.MustCompile(`^\s{0,3}(?:>\s?)+`)
+	tableRulePattern   = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(?:\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	spacePattern       = regexp.MustCompile(`\s+`)
+)
+
+// abbreviations never end a sentence even when followed by a capitalized word
+var abbreviations = toSet(`e.g i.e etc vs approx fig no nos vol dept est min max mr mrs ms dr prof sr jr st
+inc ltd co corp jan feb mar apr jun jul aug sep sept oct nov dec cf al ca ref`)
+
+// StripMarkdown converts Markdown to plain text. Code blocks and HTML blocks
+// are dropped, inline markup is reduced to its text, and each paragraph, list
+// item or heading ends up on its own line.
+func StripMarkdown(content string) string {
+	var lines []string
+	for _, line := range markdown.SplitLines(proseText(content)) {
+		if tableRulePattern.MatchString(line) {
+			continue
+		}
+		line = headingMarkPattern.ReplaceAllString(line, "")
+		line = quoteMarkPattern.ReplaceAllString(line, "")
+
+		isItem := listMarkerPattern.MatchString(line)
+		line = listMarkerPattern.ReplaceAllString(line, "")
+
+		line = imagePattern.ReplaceAllString(line, "$1")
+		line = linkPattern.ReplaceAllString(line, "$1")
+		line = refLinkPattern.ReplaceAllString(line, "$1")
+		line = autolinkPattern.ReplaceAllString(line, "$1")
+		line = htmlTagPattern.ReplaceAllString(line, "")
+		line = strings.ReplaceAll(line, "`", "")
+		line = strongPattern.ReplaceAllString(line, "$2")
+		line = emphasisPattern.ReplaceAllString(line, "$1$2$3")
+		line = strikePattern.ReplaceAllString(line, "$1")
+		line = strings.ReplaceAll(line, "|", " ")
+		line = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+
+		switch {
+		case line == "":
+			lines = append(lines, "")
+		case isItem || len(lines) == 0 || lines[len(lines)-1] == "":
+			lines = append(lines, line)
+		default:
+			// Continuation lines join their paragraph
+			lines[len(lines)-1] += " " + line
+		}
+	}
+
+	var paragraphs []string
+	for _, line := range lines {
+		if line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+// SplitSentences splits plain text into sentences. Line breaks always end a
+// sentence. Periods inside tokens such as "v1.2" or "config.yaml" do not, and
+// neither do periods after known abbreviations or single initials.
+func SplitSentences(text string) []string {
+	var sentences []string
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		start := 0
+		for i := 0; i < len(runes); i++ {
+			if !isSentenceEnd(runes, i) {
+				continue
+			}
+
+			// Absorb closing quotes and brackets
+			end := i + 1
+			for end < len(runes) && strings.ContainsRune(`"')]`, runes[end]) {
+				end++
+			}
+			if sentence := strings.TrimSpace(string(runes[start:end])); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = end
+			i = end - 1
+		}
+		if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+// isSentenceEnd reports whether the punctuation at position i ends a sentence
+func isSentenceEnd(runes []rune, i int) bool {
+	r := runes[i]
+	if r != '.' && r != '!' && r != '?' {
+		return false
+	}
+
+	// Look past closing quotes and brackets for whitespace and a sentence start
+	next := i + 1
+	for next < len(runes) && strings.ContainsRune(`"')]`, runes[next]) {
+		next++
+	}
+	if next < len(runes) && !unicode.IsSpace(runes[next]) {
+		return false // e.g. "v1.2", "config.yaml", "...!"
+	}
+	for next < len(runes) && unicode.IsSpace(runes[next]) {
+		next++
+	}
+	if next < len(runes) && unicode.IsLower(runes[next]) {
+		return false
+	}
+
+	if r != '.' {
+		return true
+	}
+
+	// Find the word that owns the period
+	wordStart := i
+	for wordStart > 0 && !unicode.IsSpace(runes[wordStart-1]) && runes[wordStart-1] != '(' {
+		wordStart--
+	}
+	word := strings.ToLower(strings.TrimRight(string(runes[wordStart:i]), "."))
+	if abbreviations[word] {
+		return false
+	}
+	// Single initials such as "J. Smith"
+	if utf8.RuneCountInString(word) == 1 && unicode.IsLetter([]rune(word)[0]) && next < len(runes) {
+		return false
+	}
+	return true
+}
+
+// Summarize returns the most representative sentence of a chapter, ranked with
+// TextRank over word-overlap similarity and truncated on a rune boundary.
+func Summarize(content string, maxLength int) string {
+	sentences := SplitSentences(StripMarkdown(content))
+	if len(sentences) == 0 {
+		return ""
+	}
+
+	// Prefer sentences with substance; fall back to whatever exists
+	var candidates []string
+	for _, sentence := range sentences {
+		if len(strings.Fields(sentence)) >= MinSentenceWords {
+			candidates = append(candidates, sentence)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = sentences
+	}
+
+	scores := textRank(candidates)
+	best := 0
+	for i, score := range scores {
+		if score > scores[best]+1e-9 {
+			best = i
+		}
+	}
+
+	return tokens.TruncateRunes(candidates[best], maxLength)
+}
+
+// textRank scores sentences by centrality in their similarity graph
+func textRank(sentences []string) []float64 {
+	n := len(sentences)
+	words := make([]map[string]bool, n)
+	for i, sentence := range sentences {
+		words[i] = make(map[string]bool)
+		for _, word := range wordPattern.FindAllString(strings.ToLower(sentence), -1) {
+			if len(word) >= 3 && !stopWords[word] {
+				words[i][word] = true
+			}
+		}
+	}
+
+	// Edge weights: shared words normalized by sentence lengths (Mihalcea & Tarau)
+	weights := make([][]float64, n)
+	outSum := make([]float64, n)
+	for i := range weights {
+		weights[i] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			if i == j || len(words[i]) < 2 || len(words[j]) < 2 {
+				continue
+			}
+			shared := 0
+			for word := range words[i] {
+				if words[j][word] {
+					shared++
+				}
+			}
+			if shared > 0 {
+				weights[i][j] = float64(shared) / (math.Log(float64(len(words[i]))) + math.Log(float64(len(words[j]))))
+				outSum[i] += weights[i][j]
+			}
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+	for iteration := 0; iteration < TextRankIterations; iteration++ {
+		next := make([]float64, n)
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				if weights[j][i] > 0 {
+					sum += weights[j][i] / outSum[j] * scores[j]
+				}
+			}
+			next[i] = (1 - TextRankDamping) + TextRankDamping*sum
+		}
+		scores = next
+	}
+	return scores
+}
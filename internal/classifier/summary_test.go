@@ -0,0 +1,71 @@
+package classifier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "versions and paths do not end sentences",
+			text: "Upgrade to v1.2 before editing config.yaml. Then restart.",
+			want: []string{"Upgrade to v1.2 before editing config.yaml.", "Then restart."},
+		},
+		{
+			name: "abbreviations do not end sentences",
+			text: "Use a cache, e.g. Redis for sessions. Keep it small.",
+			want: []string{"Use a cache, e.g. Redis for sessions.", "Keep it small."},
+		},
+		{
+			name: "lowercase continuation is not a boundary",
+			text: "Run npm i. then build. Done!",
+			want: []string{"Run npm i. then build.", "Done!"},
+		},
+		{
+			name: "line breaks end sentences",
+			text: "First item\nSecond item",
+			want: []string{"First item", "Second item"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitSentences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	content := "- **Bold** item with `code` and a [link](https://example.com)\n" +
+		"- _emphasis_ keeps snake_case_names\n\n" +
+		"> quoted text\n\n" +
+		"```go\nfunc hidden() {}\n```\n"
+
+	want := "Bold item with code and a link\nemphasis keeps snake_case_names\nquoted text"
+	if got := StripMarkdown(content); got != want {
+		t.Errorf("StripMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	content := `Sessions are stored in Redis.
+Redis sessions expire after one hour and Redis keys are namespaced per tenant.
+Coffee is available in the kitchen.
+Tenant sessions in Redis are evicted when the tenant is suspended.`
+
+	got := Summarize(content, MaxSummaryLength)
+	if !strings.Contains(got, "Redis") {
+		t.Errorf("Summarize() = %q, want the most central Redis sentence", got)
+	}
+	if strings.Contains(got, "Coffee") {
+		t.Errorf("Summarize() picked an off-topic sentence: %q", got)
+	}
+}
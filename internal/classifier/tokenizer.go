@@ -0,0 +1,264 @@
+package classifier
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/angelcodes95/contindex/internal/tokens"
+)
+
+// The cl100k_base and o200k_base rank files of the tiktoken project, gzipped
+//
+//go:embed bpe/*.tiktoken.gz
+var bpeFS embed.FS
+
+// Tokenizer names
+const (
+	HeuristicTokenizer = tokens.Heuristic // Characters divided by TokenEstimationRatio
+	CL100KTokenizer    = "cl100k_base"    // BPE encoding of GPT-4 and GPT-3.5
+	O200KTokenizer     = "o200k_base"     // BPE encoding of GPT-4o
+	DefaultTokenizer   = tokens.Default
+)
+
+// Tokenizer counts the tokens a model would see for a piece of text
+type Tokenizer interface {
+	Name() string
+	Count(text string) int
+}
+
+// Pre-tokenization patterns of the tiktoken encodings. The upstream patterns
+// end with `\s+(?!\S)|\s+`; RE2 has no lookahead, so splitPieces emulates it.
+var (
+	cl100kPattern = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+	o200kPattern  = regexp.MustCompile(`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+`)
+)
+
+// encodings maps tokenizer names to their embedded rank file and the
+// pre-tokenization pattern of the encoding
+var encodings = map[string]struct {
+	file    string
+	pattern *regexp.Regexp
+}{
+	CL100KTokenizer: {file: "bpe/cl100k_base.tiktoken.gz", pattern: cl100kPattern},
+	O200KTokenizer:  {file: "bpe/o200k_base.tiktoken.gz", pattern: o200kPattern},
+}
+
+var (
+	rankCache   = make(map[string]map[string]int)
+	rankCacheMu sync.Mutex
+)
+
+// NewTokenizer returns a tokenizer by name: the heuristic, or one of the
+// embedded BPE encodings. Encoding names may leave out the _base suffix.
+func NewTokenizer(name string) (Tokenizer, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == HeuristicTokenizer {
+		return heuristicTokenizer{}, nil
+	}
+	if !strings.HasSuffix(name, "_base") {
+		name += "_base"
+	}
+
+	encoding, ok := encodings[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer '%s' (available: %s)", strings.TrimSuffix(name, "_base"), strings.Join(TokenizerNames(), ", "))
+	}
+	ranks, err := loadRanks(encoding.file)
+	if err != nil {
+		return nil, err
+	}
+	return &BPETokenizer{name: name, ranks: ranks, pattern: encoding.pattern}, nil
+}
+
+// TokenizerNames lists the tokenizer names NewTokenizer accepts
+func TokenizerNames() []string {
+	names := []string{HeuristicTokenizer}
+	for name := range encodings {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// heuristicTokenizer estimates tokens from the byte length of the text
+type heuristicTokenizer struct{}
+
+func (heuristicTokenizer) Name() string { return HeuristicTokenizer }
+
+func (heuristicTokenizer) Count(text string) int {
+	return len(text) / TokenEstimationRatio
+}
+
+// BPETokenizer counts tokens with byte-pair encoding over a tiktoken rank table
+type BPETokenizer struct {
+	name    string
+	ranks   map[string]int
+	pattern *regexp.Regexp
+}
+
+// Name returns the encoding name
+func (t *BPETokenizer) Name() string { return t.name }
+
+// Count returns the exact number of BPE tokens in text
+func (t *BPETokenizer) Count(text string) int {
+	count := 0
+	for _, piece := range splitPieces(t.pattern, text) {
+		if _, ok := t.ranks[piece]; ok {
+			count++
+			continue
+		}
+		count += len(bytePairMerge([]byte(piece), t.ranks))
+	}
+	return count
+}
+
+// splitPieces applies a pre-tokenization pattern, emulating the trailing
+// `\s+(?!\S)` alternative: a whitespace run followed by a non-space character
+// gives up its last character so it can prefix the next word.
+func splitPieces(pattern *regexp.Regexp, text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		loc := pattern.FindStringIndex(text)
+		if loc == nil {
+			break
+		}
+		start, end := loc[0], loc[1]
+		if start > 0 {
+			pieces = append(pieces, text[:start])
+		}
+
+		piece := text[start:end]
+		if end < len(text) && isAllSpace(piece) && !strings.ContainsAny(piece, "\r\n") {
+			if next, _ := utf8.DecodeRuneInString(text[end:]); !unicode.IsSpace(next) && utf8.RuneCountInString(piece) > 1 {
+				_, lastSize := utf8.DecodeLastRuneInString(piece)
+				end -= lastSize
+				piece = text[start:end]
+			}
+		}
+		if end == start {
+			end = start + 1
+			piece = text[start:end]
+		}
+
+		pieces = append(pieces, piece)
+		text = text[end:]
+	}
+	return pieces
+}
+
+func isAllSpace(s string) bool {
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// bytePairMerge repeatedly merges the adjacent pair with the lowest rank until
+// no mergeable pair remains, returning the resulting token boundaries
+func bytePairMerge(piece []byte, ranks map[string]int) [][]byte {
+	parts := make([][]byte, len(piece))
+	for i := range piece {
+		parts[i] = piece[i : i+1]
+	}
+
+	for len(parts) > 1 {
+		bestRank := -1
+		bestIndex := -1
+		for i := 0; i < len(parts)-1; i++ {
+			merged := piece[offsetOf(piece, parts[i]) : offsetOf(piece, parts[i+1])+len(parts[i+1])]
+			if rank, ok := ranks[string(merged)]; ok && (bestRank < 0 || rank < bestRank) {
+				bestRank = rank
+				bestIndex = i
+			}
+		}
+		if bestIndex < 0 {
+			break
+		}
+
+		start := offsetOf(piece, parts[bestIndex])
+		end := offsetOf(piece, parts[bestIndex+1]) + len(parts[bestIndex+1])
+		parts[bestIndex] = piece[start:end]
+		parts = append(parts[:bestIndex+1], parts[bestIndex+2:]...)
+	}
+	return parts
+}
+
+// offsetOf returns the position of a sub-slice within its parent slice
+func offsetOf(parent, part []byte) int {
+	return cap(parent) - cap(part)
+}
+
+// loadRanks reads an embedded rank file once and caches it
+func loadRanks(file string) (map[string]int, error) {
+	rankCacheMu.Lock()
+	defer rankCacheMu.Unlock()
+
+	if ranks, ok := rankCache[file]; ok {
+		return ranks, nil
+	}
+
+	data, err := bpeFS.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("missing embedded rank file %s: %w", file, err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded rank file %s: %w", file, err)
+	}
+	defer reader.Close()
+
+	ranks, err := parseRanks(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded rank file %s: %w", file, err)
+	}
+	rankCache[file] = ranks
+	return ranks, nil
+}
+
+// parseRanks reads the tiktoken format: one "<base64 token> <rank>" pair per line
+func parseRanks(r io.Reader) (map[string]int, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected token and rank", lineNum)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("no ranks found")
+	}
+	return ranks, nil
+}
@@ -0,0 +1,117 @@
+package classifier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitPieces(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "words carry their leading space",
+			text: "hello world",
+			want: []string{"hello", " world"},
+		},
+		{
+			name: "whitespace run leaves one space for the next word",
+			text: "a   b",
+			want: []string{"a", "  ", " b"},
+		},
+		{
+			name: "contractions and digit groups",
+			text: "I'm 12345",
+			want: []string{"I", "'m", " ", "123", "45"},
+		},
+		{
+			name: "newlines are separate pieces",
+			text: "x\n\ny",
+			want: []string{"x", "\n\n", "y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitPieces(cl100kPattern, tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitPieces() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBPETokenizerCount(t *testing.T) {
+	ranks := make(map[string]int)
+	for rank, token := range []string{"a", "b", "c", " ", "ab", "abc", "bc", " a"} {
+		ranks[token] = rank
+	}
+	tokenizer := &BPETokenizer{name: "test", ranks: ranks, pattern: cl100kPattern}
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "abc", want: 1},   // Whole piece is a token
+		{text: "abcab", want: 2}, // ab + c + ab merges to abc + ab
+		{text: "cba", want: 3},   // No merges apply
+		{text: "abc abc", want: 3},
+		{text: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tokenizer.Count(tt.text); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTokenizer(t *testing.T) {
+	heuristic, err := NewTokenizer("")
+	if err != nil || heuristic.Name() != HeuristicTokenizer {
+		t.Fatalf("NewTokenizer(\"\") = %v, %v, want heuristic tokenizer", heuristic, err)
+	}
+	if got := heuristic.Count(strings.Repeat("x", 40)); got != 10 {
+		t.Errorf("heuristic Count() = %d, want 10", got)
+	}
+
+	if _, err := NewTokenizer("unknown"); err == nil || !strings.Contains(err.Error(), CL100KTokenizer) {
+		t.Errorf("NewTokenizer(\"unknown\") error = %v, want the available tokenizers", err)
+	}
+}
+
+func TestEmbeddedEncodings(t *testing.T) {
+	// Counts as reported by the tiktoken reference implementation
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{CL100KTokenizer, "hello world", 2},
+		{CL100KTokenizer, "tiktoken is great!", 6},
+		{"cl100k", "hello world", 2},
+		{CL100KTokenizer, "The quick brown fox jumps over the lazy dog.", 10},
+		{O200KTokenizer, "hello world", 2},
+		{O200KTokenizer, "The quick brown fox jumps over the lazy dog.", 10},
+		{"o200k", "hello world", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.text, func(t *testing.T) {
+			tokenizer, err := NewTokenizer(tt.name)
+			if err != nil {
+				t.Fatalf("NewTokenizer(%q) error = %v", tt.name, err)
+			}
+			if !strings.HasSuffix(tokenizer.Name(), "_base") {
+				t.Errorf("Name() = %q, want the encoding name", tokenizer.Name())
+			}
+			if got := tokenizer.Count(tt.text); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package classifier
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/validation"
+)
+
+//go:embed vocab/*.json
+var builtinVocabFS embed.FS
+
+// Vocabulary pack locations and defaults
+const (
+	DefaultPackName    = "backend"               // Pack used when none is selected
+	AllPacks           = "all"                   // Selects every known pack
+	ProjectVocabFile   = ".contindex/vocab.json" // Project-level packs, relative to the project root
+	UserVocabDirectory = "contindex/vocab"       // User-level pack directory, relative to the user config dir
+)
+
+// Term maps words or phrases to the descriptor used in filenames
+type Term struct {
+	Descriptor string   `json:"descriptor"`         // Filename descriptor, e.g. "postgresql"
+	Patterns   []string `json:"patterns"`           // Words or phrases matched on word boundaries
+	Synonyms   []string `json:"synonyms,omitempty"` // Weaker signals, scored at SynonymWeight
+	Weight     float64  `json:"weight,omitempty"`   // Score multiplier, defaults to 1
+}
+
+// Pack is a named set of classification terms for a domain
+type Pack struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Technology  []Term `json:"technology,omitempty"`
+	Function    []Term `json:"function,omitempty"`
+	Source      string `json:"-"` // Where the pack was loaded from
+}
+
+// projectVocabFile is the layout of the project-level vocabulary file
+type projectVocabFile struct {
+	Packs []*Pack `json:"packs"`
+}
+
+// Vocabulary is a compiled selection of packs used to classify sections
+type Vocabulary struct {
+	Packs      []*Pack
+	technology []termMatcher
+	function   []termMatcher
+}
+
+// PackRegistry holds every known pack by name. Later sources override earlier
+// ones: built-in packs, then the user directory, then the project file.
+type PackRegistry struct {
+	packs map[string]*Pack
+}
+
+var builtinPacks = mustLoadBuiltinPacks()
+
+// DefaultVocabulary returns the vocabulary built from the default built-in pack
+func DefaultVocabulary() *Vocabulary {
+	vocabulary, err := NewVocabulary(builtinPacks[DefaultPackName])
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in vocabulary: %v", err))
+	}
+	return vocabulary
+}
+
+// NewVocabulary compiles packs into a vocabulary; earlier packs win score ties
+func NewVocabulary(packs ...*Pack) (*Vocabulary, error) {
+	vocabulary := &Vocabulary{Packs: packs}
+	for _, pack := range packs {
+		technology, err := compileTerms(pack.Technology)
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", pack.Name, err)
+		}
+		function, err := compileTerms(pack.Function)
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", pack.Name, err)
+		}
+		vocabulary.technology = append(vocabulary.technology, technology...)
+		vocabulary.function = append(vocabulary.function, function...)
+	}
+	return vocabulary, nil
+}
+
+// PackNames returns the names of the packs in the vocabulary
+func (v *Vocabulary) PackNames() []string {
+	var names []string
+	for _, pack := range v.Packs {
+		names = append(names, pack.Name)
+	}
+	return names
+}
+
+// LoadPackRegistry collects built-in, user-level and project-level packs
+func LoadPackRegistry(projectRoot string) (*PackRegistry, error) {
+	registry := &PackRegistry{packs: make(map[string]*Pack)}
+	for name, pack := range builtinPacks {
+		registry.packs[name] = pack
+	}
+
+	if configDir, err := os.UserConfigDir(); err == nil {
+		if err := registry.loadDirectory(filepath.Join(configDir, UserVocabDirectory)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := registry.loadProjectFile(filepath.Join(projectRoot, ProjectVocabFile)); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// Names returns all known pack names in sorted order
+func (r *PackRegistry) Names() []string {
+	names := make([]string, 0, len(r.packs))
+	for name := range r.packs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pack returns a pack by name
+func (r *PackRegistry) Pack(name string) (*Pack, bool) {
+	pack, ok := r.packs[name]
+	return pack, ok
+}
+
+// Vocabulary compiles the named packs in the given order. An empty selection
+// uses the default pack and "all" selects every known pack.
+func (r *PackRegistry) Vocabulary(names []string) (*Vocabulary, error) {
+	if len(names) == 0 {
+		names = []string{DefaultPackName}
+	}
+
+	var packs []*Pack
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == AllPacks {
+			return r.Vocabulary(r.allNames())
+		}
+		if seen[name] {
+			continue
+		}
+		pack, ok := r.packs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown vocabulary pack '%s' (available: %s)", name, strings.Join(r.Names(), ", "))
+		}
+		seen[name] = true
+		packs = append(packs, pack)
+	}
+
+	return NewVocabulary(packs...)
+}
+
+// allNames lists every pack with the default pack first so it wins ties
+func (r *PackRegistry) allNames() []string {
+	names := []string{DefaultPackName}
+	for _, name := range r.Names() {
+		if name != DefaultPackName {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// loadDirectory reads one pack per .json file from a directory, if it exists
+func (r *PackRegistry) loadDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read vocabulary directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read vocabulary pack %s: %w", path, err)
+		}
+		pack, err := parsePack(data, path)
+		if err != nil {
+			return err
+		}
+		r.packs[pack.Name] = pack
+	}
+	return nil
+}
+
+// loadProjectFile reads the project-level vocabulary file, if it exists
+func (r *PackRegistry) loadProjectFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read vocabulary file %s: %w", path, err)
+	}
+
+	var file projectVocabFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("invalid vocabulary file %s: %w", path, err)
+	}
+	for _, pack := range file.Packs {
+		pack.Source = path
+		if err := validatePack(pack); err != nil {
+			return fmt.Errorf("invalid vocabulary file %s: %w", path, err)
+		}
+		r.packs[pack.Name] = pack
+	}
+	return nil
+}
+
+// parsePack decodes and validates a single pack
+func parsePack(data []byte, source string) (*Pack, error) {
+	var pack Pack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("invalid vocabulary pack %s: %w", source, err)
+	}
+	pack.Source = source
+	if err := validatePack(&pack); err != nil {
+		return nil, fmt.Errorf("invalid vocabulary pack %s: %w", source, err)
+	}
+	return &pack, nil
+}
+
+// validatePack checks pack and descriptor names and that every term has patterns
+func validatePack(pack *Pack) error {
+	if err := validation.ValidateTemplateName(pack.Name); err != nil {
+		return fmt.Errorf("invalid pack name: %w", err)
+	}
+	for _, term := range append(append([]Term{}, pack.Technology...), pack.Function...) {
+		if err := validation.ValidateCategoryName(term.Descriptor); err != nil {
+			return fmt.Errorf("pack %s: invalid descriptor: %w", pack.Name, err)
+		}
+		if len(term.Patterns) == 0 {
+			return fmt.Errorf("pack %s: descriptor %s has no patterns", pack.Name, term.Descriptor)
+		}
+		if term.Weight < 0 {
+			return fmt.Errorf("pack %s: descriptor %s has a negative weight", pack.Name, term.Descriptor)
+		}
+	}
+	if _, err := NewVocabulary(pack); err != nil {
+		return err
+	}
+	return nil
+}
+
+// mustLoadBuiltinPacks parses the embedded packs; they are part of the binary,
+// so a malformed one is a programming error
+func mustLoadBuiltinPacks() map[string]*Pack {
+	entries, err := builtinVocabFS.ReadDir("vocab")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read built-in vocabulary: %v", err))
+	}
+
+	packs := make(map[string]*Pack)
+	for _, entry := range entries {
+		path := "vocab/" + entry.Name()
+		data, err := builtinVocabFS.ReadFile(path)
+		if err != nil {
+			panic(fmt.Sprintf("failed to read built-in vocabulary: %v", err))
+		}
+		pack, err := parsePack(data, "built-in")
+		if err != nil {
+			panic(err.Error())
+		}
+		packs[pack.Name] = pack
+	}
+	return packs
+}
@@ -0,0 +1,92 @@
+package classifier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPackRegistry(t *testing.T) {
+	projectRoot := t.TempDir()
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	userDir := filepath.Join(configDir, UserVocabDirectory)
+	if err := os.MkdirAll(userDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	userPack := `{"name": "games", "technology": [{"descriptor": "unity", "patterns": ["unity"]}]}`
+	if err := os.WriteFile(filepath.Join(userDir, "games.json"), []byte(userPack), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projectFile := `{"packs": [
+		{"name": "games", "technology": [{"descriptor": "godot", "patterns": ["godot"]}]},
+		{"name": "robotics", "function": [{"descriptor": "motion-planning", "patterns": ["motion planning"], "synonyms": ["trajectory"], "weight": 2}]}
+	]}`
+	if err := os.MkdirAll(filepath.Join(projectRoot, ".contindex"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectRoot, ProjectVocabFile), []byte(projectFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := LoadPackRegistry(projectRoot)
+	if err != nil {
+		t.Fatalf("LoadPackRegistry() unexpected error = %v", err)
+	}
+
+	for _, name := range []string{"backend", "frontend", "mobile", "data-ml", "infra", "security", "games", "robotics"} {
+		if _, ok := registry.Pack(name); !ok {
+			t.Errorf("LoadPackRegistry() missing pack %q", name)
+		}
+	}
+
+	games, _ := registry.Pack("games")
+	if games.Technology[0].Descriptor != "godot" {
+		t.Errorf("project pack should override user pack, got descriptor %q", games.Technology[0].Descriptor)
+	}
+
+	vocabulary, err := registry.Vocabulary([]string{"robotics"})
+	if err != nil {
+		t.Fatalf("Vocabulary() unexpected error = %v", err)
+	}
+	got := classify(vocabulary.function, "Arm control", "Compute the trajectory before motion planning runs.")
+	if got.Descriptor != "motion-planning" {
+		t.Errorf("classify() with project pack = %q, want %q", got.Descriptor, "motion-planning")
+	}
+
+	if _, err := registry.Vocabulary([]string{"missing"}); err == nil || !strings.Contains(err.Error(), "unknown vocabulary pack") {
+		t.Errorf("Vocabulary() with unknown pack error = %v, want unknown pack error", err)
+	}
+
+	all, err := registry.Vocabulary([]string{AllPacks})
+	if err != nil {
+		t.Fatalf("Vocabulary(all) unexpected error = %v", err)
+	}
+	if names := all.PackNames(); names[0] != DefaultPackName || len(names) != len(registry.Names()) {
+		t.Errorf("Vocabulary(all) packs = %v, want every pack with %s first", names, DefaultPackName)
+	}
+}
+
+func TestParsePackValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "missing name", data: `{"technology": [{"descriptor": "x", "patterns": ["x"]}]}`},
+		{name: "invalid descriptor", data: `{"name": "p", "technology": [{"descriptor": "Bad Name", "patterns": ["x"]}]}`},
+		{name: "no patterns", data: `{"name": "p", "function": [{"descriptor": "x"}]}`},
+		{name: "negative weight", data: `{"name": "p", "function": [{"descriptor": "x", "patterns": ["x"], "weight": -1}]}`},
+		{name: "malformed json", data: `{"name": `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePack([]byte(tt.data), "test"); err == nil {
+				t.Errorf("parsePack() expected error but got nil")
+			}
+		})
+	}
+}
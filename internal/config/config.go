@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 )
 
 // Error messages constants
@@ -13,71 +14,145 @@ const (
 // Note: Category-based organization has been replaced with semantic file naming
 // Individual descriptively-named files are created instead of rigid categories
 
-// SupportedTemplates defines available template types
-var SupportedTemplates = []string{
-	"generic",
-	"claude",
-	"cursor",
-	"copilot",
-	"gemini",
+// AllTemplates is the template name that selects every tool-specific built-in template
+const AllTemplates = "all"
+
+// TemplateConfig defines the structure for template configurations. Every
+// field but Name and Dir is read from the template.json of the template.
+type TemplateConfig struct {
+	Name            string      `json:"-"`                 // Template name
+	Extends         string      `json:"extends,omitempty"` // Template or partial this template builds on
+	Description     string      `json:"description"`       // One-line description for template list
+	MainFile        string      `json:"main_file"`         // The main context file name
+	SubDir          string      `json:"subdir,omitempty"`  // Optional subdirectory (e.g., .github for copilot)
+	ReferenceSyntax string      `json:"reference_syntax"`  // How the tool references a chapter, %s is its path
+	Tools           []string    `json:"tools"`             // AI tools the template is written for
+	TokenLimits     TokenLimits `json:"token_limits"`
+	ChapterFile     string      `json:"chapter_file,omitempty"` // Name of the file written per chapter next to the index, %s is the chapter; empty for none
+	InAll           *bool       `json:"in_all,omitempty"`       // Whether "all" selects the template; unset means only built-in templates
+	Dir             string      `json:"-"`                      // Directory holding template.md on disk, empty for built-in templates
 }
 
-// TemplateConfigs maps template names to their file configurations
-var TemplateConfigs = map[string]TemplateConfig{
-	"generic": {
-		MainFile: "template.md",
-		SubDir:   "",
-	},
-	"claude": {
-		MainFile: "CLAUDE.md",
-		SubDir:   "",
-	},
-	"cursor": {
-		MainFile: "AGENTS.md",
-		SubDir:   "",
-	},
-	"copilot": {
-		MainFile: "copilot-instructions.md",
-		SubDir:   ".github",
-	},
-	"gemini": {
-		MainFile: "GEMINI.md",
-		SubDir:   "",
-	},
+// TokenLimits are token budgets of a template; zero means no limit
+type TokenLimits struct {
+	Index   int `json:"index,omitempty"`   // Update warns when the index file grows beyond this
+	Chapter int `json:"chapter,omitempty"` // Default for convert --max-chapter-tokens
 }
 
-// TemplateConfig defines the structure for template configurations
-type TemplateConfig struct {
-	MainFile string // The main context file name
-	SubDir   string // Optional subdirectory (e.g., .github for copilot)
+// Reference returns how the tool of the template references a chapter path
+func (tc TemplateConfig) Reference(chapterPath string) string {
+	return fmt.Sprintf(tc.ReferenceSyntax, chapterPath)
+}
+
+// ChapterFileName returns the name of the file the template writes for a
+// chapter. Chapters in subdirectories are flattened, so guides/setup.md
+// becomes guides-setup; callers must reject chapters whose names collide.
+func (tc TemplateConfig) ChapterFileName(chapterPath string) string {
+	name := strings.ReplaceAll(strings.TrimSuffix(chapterPath, ".md"), "/", "-")
+	return fmt.Sprintf(tc.ChapterFile, name)
+}
+
+// InAllTemplates reports whether "all" selects the template. Built-in
+// templates are selected unless their template.json sets "in_all": false;
+// other templates only when it sets "in_all": true.
+func (tc TemplateConfig) InAllTemplates() bool {
+	if tc.InAll != nil {
+		return *tc.InAll
+	}
+	return isBuiltin(tc.Name)
+}
+
+// Builtin reports whether the template is embedded in the binary
+func (tc TemplateConfig) Builtin() bool {
+	return tc.Dir == ""
 }
 
-// ProjectConfig holds configuration for a contindex project
+// ProjectConfig holds configuration for a contindex project. Commands load it
+// once with Load and read every setting from it.
 type ProjectConfig struct {
-	ContextDir  string // Directory containing individual context files
-	Template    string // Template type being used
-	MainFile    string // Main context file path
-	ProjectRoot string // Root directory of the project
+	ContextDir  string   // Directory containing individual context files
+	BackupDir   string   // Directory for backups of converted files
+	Template    string   // Template type being used
+	MainFile    string   // Main context file path
+	Targets     []string // Templates to write index files for, starting with Template
+	ProjectName string   // Project name shown in the index
+	Tokenizer   string   // Token counter name or rank file, empty for the default
+	ProjectRoot string   // Root directory of the project
+
+	saved Settings // Settings written by Save
 }
 
-// DefaultConfig creates a default project configuration
+// DefaultConfig creates a project configuration from the built-in defaults,
+// ignoring any config file or environment variables
 func DefaultConfig(projectRoot string) *ProjectConfig {
-	return &ProjectConfig{
-		ContextDir:  filepath.Join(projectRoot, "context"),
-		Template:    "generic",
-		MainFile:    filepath.Join(projectRoot, "template.md"),
-		ProjectRoot: projectRoot,
+	pc, err := DefaultSettings().resolve(projectRoot)
+	if err != nil {
+		panic(err) // The built-in defaults are always valid
+	}
+	pc.saved = DefaultSettings()
+	return pc
+}
+
+// ValidateTemplate checks if a template is available to a project
+func ValidateTemplate(template, projectRoot string) error {
+	_, err := LookupTemplate(template, projectRoot)
+	return err
+}
+
+// ExpandTemplates validates a list of template names, expanding "all" to the
+// templates that opt into it with in_all, and dropping duplicates
+func ExpandTemplates(names []string, projectRoot string) ([]string, error) {
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			targets = append(targets, name)
+		}
+	}
+
+	for _, name := range names {
+		if name == AllTemplates {
+			all, err := allTemplates(projectRoot)
+			if err != nil {
+				return nil, err
+			}
+			for _, template := range all {
+				add(template)
+			}
+			continue
+		}
+		if err := ValidateTemplate(name, projectRoot); err != nil {
+			return nil, err
+		}
+		add(name)
 	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%s: no template given", ErrUnsupportedTemplate)
+	}
+	return targets, nil
 }
 
-// ValidateTemplate checks if a template name is supported
-func ValidateTemplate(template string) error {
-	for _, supported := range SupportedTemplates {
-		if template == supported {
-			return nil
+// allTemplates returns the templates "all" selects, in the order of
+// SupportedTemplates
+func allTemplates(projectRoot string) ([]string, error) {
+	configs, err := TemplateConfigs(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	names, err := SupportedTemplates(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, name := range names {
+		if configs[name].InAllTemplates() {
+			selected = append(selected, name)
 		}
 	}
-	return fmt.Errorf("%s: %s", ErrUnsupportedTemplate, template)
+	return selected, nil
 }
 
 // ValidateCategory is deprecated - categories are no longer used
@@ -85,11 +160,11 @@ func ValidateTemplate(template string) error {
 
 // GetMainFileForTemplate returns the appropriate main file name for a template
 func GetMainFileForTemplate(template string, projectRoot string) (string, error) {
-	if err := ValidateTemplate(template); err != nil {
+	config, err := LookupTemplate(template, projectRoot)
+	if err != nil {
 		return "", err
 	}
 
-	config := TemplateConfigs[template]
 	if config.SubDir != "" {
 		return filepath.Join(projectRoot, config.SubDir, config.MainFile), nil
 	}
@@ -98,16 +173,37 @@ func GetMainFileForTemplate(template string, projectRoot string) (string, error)
 
 // UpdateForTemplate modifies a ProjectConfig to use a specific template
 func (pc *ProjectConfig) UpdateForTemplate(template string) error {
-	if err := ValidateTemplate(template); err != nil {
-		return err
-	}
-
 	pc.Template = template
 	mainFile, err := GetMainFileForTemplate(template, pc.ProjectRoot)
 	if err != nil {
 		return err
 	}
 	pc.MainFile = mainFile
+	pc.Targets = []string{template}
 
 	return nil
 }
+
+// ForTemplate returns a copy of the configuration that writes the index file
+// of one template
+func (pc *ProjectConfig) ForTemplate(template string) (*ProjectConfig, error) {
+	target := *pc
+	if err := target.UpdateForTemplate(template); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// TargetConfigs returns one configuration per target template
+func (pc *ProjectConfig) TargetConfigs() []*ProjectConfig {
+	var configs []*ProjectConfig
+	for _, template := range pc.Targets {
+		// Targets are validated when the configuration is loaded
+		target, err := pc.ForTemplate(template)
+		if err != nil {
+			continue
+		}
+		configs = append(configs, target)
+	}
+	return configs
+}
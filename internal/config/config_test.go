@@ -49,7 +49,7 @@ func TestValidateTemplate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidateTemplate(tt.template)
+			err := ValidateTemplate(tt.template, t.TempDir())
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("ValidateTemplate() expected error but got nil")
@@ -144,11 +144,11 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("DefaultConfig() ContextDir = %v, want %v", config.ContextDir, expectedContextDir)
 	}
 
-	if config.Template != "generic" {
-		t.Errorf("DefaultConfig() Template = %v, want %v", config.Template, "generic")
+	if config.Template != "claude" {
+		t.Errorf("DefaultConfig() Template = %v, want %v", config.Template, "claude")
 	}
 
-	expectedMainFile := filepath.Join(projectRoot, "template.md")
+	expectedMainFile := filepath.Join(projectRoot, "CLAUDE.md")
 	if config.MainFile != expectedMainFile {
 		t.Errorf("DefaultConfig() MainFile = %v, want %v", config.MainFile, expectedMainFile)
 	}
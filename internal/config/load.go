@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileName is the project configuration file, relative to the project root
+const FileName = ".contindex.json"
+
+// Built-in defaults used when no flag, environment variable or config file sets a value
+const (
+	DefaultTemplate   = "claude"
+	DefaultContextDir = "context"
+	DefaultBackupDir  = "backup"
+)
+
+// Environment variables that override the config file
+const (
+	EnvTemplate    = "CONTINDEX_TEMPLATE"
+	EnvContextDir  = "CONTINDEX_CONTEXT_DIR"
+	EnvBackupDir   = "CONTINDEX_BACKUP_DIR"
+	EnvProjectName = "CONTINDEX_PROJECT_NAME"
+	EnvTokenizer   = "CONTINDEX_TOKENIZER"
+)
+
+// Settings is one layer of configuration. Empty fields leave the value of
+// the layer below in place. Directories are relative to the project root.
+type Settings struct {
+	Template    TemplateList `json:"template,omitempty"`
+	ContextDir  string       `json:"context_dir,omitempty"`
+	BackupDir   string       `json:"backup_dir,omitempty"`
+	ProjectName string       `json:"project_name,omitempty"`
+	Tokenizer   string       `json:"tokenizer,omitempty"`
+}
+
+// TemplateList is one or more template names. The config file accepts a
+// single name, a comma-separated string or a JSON list.
+type TemplateList []string
+
+// ParseTemplateList splits a comma-separated list of template names
+func ParseTemplateList(value string) TemplateList {
+	var list TemplateList
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// UnmarshalJSON accepts a string or a list of strings
+func (l *TemplateList) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*l = ParseTemplateList(value)
+		return nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("template must be a string or a list of strings")
+	}
+	*l = TemplateList(names)
+	return nil
+}
+
+// MarshalJSON writes a single template as a string and several as a list
+func (l TemplateList) MarshalJSON() ([]byte, error) {
+	if len(l) == 1 {
+		return json.Marshal(l[0])
+	}
+	return json.Marshal([]string(l))
+}
+
+// DefaultSettings returns the built-in defaults. An empty tokenizer selects
+// the classifier default; an empty project name uses the directory name.
+func DefaultSettings() Settings {
+	return Settings{
+		Template:   TemplateList{DefaultTemplate},
+		ContextDir: DefaultContextDir,
+		BackupDir:  DefaultBackupDir,
+	}
+}
+
+// EnvSettings reads the CONTINDEX_* environment variables
+func EnvSettings() Settings {
+	return Settings{
+		Template:    ParseTemplateList(os.Getenv(EnvTemplate)),
+		ContextDir:  os.Getenv(EnvContextDir),
+		BackupDir:   os.Getenv(EnvBackupDir),
+		ProjectName: os.Getenv(EnvProjectName),
+		Tokenizer:   os.Getenv(EnvTokenizer),
+	}
+}
+
+// ReadSettings reads the config file of a project. A missing file returns
+// empty settings without error.
+func ReadSettings(projectRoot string) (Settings, error) {
+	var settings Settings
+
+	path := filepath.Join(projectRoot, FileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return settings, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// Merge returns s with every non-empty field of over applied on top
+func (s Settings) Merge(over Settings) Settings {
+	if len(over.Template) > 0 {
+		s.Template = over.Template
+	}
+	if over.ContextDir != "" {
+		s.ContextDir = over.ContextDir
+	}
+	if over.BackupDir != "" {
+		s.BackupDir = over.BackupDir
+	}
+	if over.ProjectName != "" {
+		s.ProjectName = over.ProjectName
+	}
+	if over.Tokenizer != "" {
+		s.Tokenizer = over.Tokenizer
+	}
+	return s
+}
+
+// Load resolves the configuration of a project. Flags take precedence over
+// environment variables, which take precedence over the config file, which
+// takes precedence over the built-in defaults.
+func Load(projectRoot string, flags Settings) (*ProjectConfig, error) {
+	file, err := ReadSettings(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := DefaultSettings().Merge(file).Merge(EnvSettings()).Merge(flags)
+	pc, err := settings.resolve(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// Environment variables are transient, so they are never saved
+	pc.saved = DefaultSettings().Merge(file).Merge(flags)
+	return pc, nil
+}
+
+// resolve turns settings into a project configuration rooted at projectRoot
+func (s Settings) resolve(projectRoot string) (*ProjectConfig, error) {
+	pc := &ProjectConfig{
+		ContextDir:  resolvePath(projectRoot, s.ContextDir),
+		BackupDir:   resolvePath(projectRoot, s.BackupDir),
+		ProjectName: s.ProjectName,
+		Tokenizer:   s.Tokenizer,
+		ProjectRoot: projectRoot,
+	}
+	if pc.ProjectName == "" {
+		if abs, err := filepath.Abs(projectRoot); err == nil {
+			pc.ProjectName = filepath.Base(abs)
+		}
+	}
+	targets, err := ExpandTemplates(s.Template, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := pc.UpdateForTemplate(targets[0]); err != nil {
+		return nil, err
+	}
+	pc.Targets = targets
+	return pc, nil
+}
+
+func resolvePath(projectRoot, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectRoot, path)
+}
+
+// Save writes the configuration to the project config file so later commands
+// use the same settings. Values that came from environment variables are not
+// saved.
+func (pc *ProjectConfig) Save() error {
+	data, err := json.MarshalIndent(pc.saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	path := filepath.Join(pc.ProjectRoot, FileName)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// RelativeContextDir returns the context directory relative to the project
+// root with forward slashes, as chapter references in the index use it
+func (pc *ProjectConfig) RelativeContextDir() string {
+	rel, err := filepath.Rel(pc.ProjectRoot, pc.ContextDir)
+	if err != nil {
+		return filepath.ToSlash(pc.ContextDir)
+	}
+	return filepath.ToSlash(rel)
+}
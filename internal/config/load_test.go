@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name         string
+		file         string
+		env          map[string]string
+		flags        Settings
+		wantTemplate string
+		wantContext  string
+	}{
+		{
+			name:         "built-in defaults",
+			wantTemplate: "claude",
+			wantContext:  "context",
+		},
+		{
+			name:         "config file over defaults",
+			file:         `{"template": "cursor", "context_dir": "docs/chapters"}`,
+			wantTemplate: "cursor",
+			wantContext:  "docs/chapters",
+		},
+		{
+			name:         "environment over config file",
+			file:         `{"template": "cursor", "context_dir": "docs/chapters"}`,
+			env:          map[string]string{EnvTemplate: "gemini"},
+			wantTemplate: "gemini",
+			wantContext:  "docs/chapters",
+		},
+		{
+			name:         "flags over environment",
+			file:         `{"template": "cursor"}`,
+			env:          map[string]string{EnvTemplate: "gemini", EnvContextDir: "env-context"},
+			flags:        Settings{Template: TemplateList{"copilot"}},
+			wantTemplate: "copilot",
+			wantContext:  "env-context",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{EnvTemplate, EnvContextDir, EnvBackupDir, EnvProjectName, EnvTokenizer} {
+				t.Setenv(key, tt.env[key])
+			}
+			root := t.TempDir()
+			if tt.file != "" {
+				if err := os.WriteFile(filepath.Join(root, FileName), []byte(tt.file), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			pc, err := Load(root, tt.flags)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if pc.Template != tt.wantTemplate {
+				t.Errorf("Load() Template = %v, want %v", pc.Template, tt.wantTemplate)
+			}
+			if got := pc.RelativeContextDir(); got != tt.wantContext {
+				t.Errorf("Load() context dir = %v, want %v", got, tt.wantContext)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "unknown template", file: `{"template": "nope"}`},
+		{name: "unknown key", file: `{"templat": "claude"}`},
+		{name: "malformed json", file: `{"template": `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, FileName), []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(root, Settings{}); err == nil {
+				t.Errorf("Load() expected error for %s", tt.file)
+			}
+		})
+	}
+}
+
+func TestSaveSkipsEnvironment(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(EnvContextDir, "from-env")
+
+	pc, err := Load(root, Settings{Template: TemplateList{"cursor"}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := pc.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	saved, err := ReadSettings(root)
+	if err != nil {
+		t.Fatalf("ReadSettings() error = %v", err)
+	}
+	want := Settings{Template: TemplateList{"cursor"}, ContextDir: DefaultContextDir, BackupDir: DefaultBackupDir}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved settings = %+v, want %+v", saved, want)
+	}
+}
+
+func TestLoadTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		flags   Settings
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "single template",
+			want: []string{"claude"},
+		},
+		{
+			name: "list in config file",
+			file: `{"template": ["cursor", "claude"]}`,
+			want: []string{"cursor", "claude"},
+		},
+		{
+			name: "comma-separated string in config file",
+			file: `{"template": "gemini, copilot"}`,
+			want: []string{"gemini", "copilot"},
+		},
+		{
+			name:  "all expands to every tool template without duplicates",
+			flags: Settings{Template: ParseTemplateList("claude,all")},
+			want:  []string{"claude", "copilot", "cursor", "gemini"},
+		},
+		{
+			name:    "unknown template in list",
+			flags:   Settings{Template: ParseTemplateList("claude,nope")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvTemplate, "")
+			root := t.TempDir()
+			if tt.file != "" {
+				if err := os.WriteFile(filepath.Join(root, FileName), []byte(tt.file), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			pc, err := Load(root, tt.flags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(pc.Targets, tt.want) {
+				t.Errorf("Load() Targets = %v, want %v", pc.Targets, tt.want)
+			}
+			if pc.Template != tt.want[0] {
+				t.Errorf("Load() Template = %v, want the first target %v", pc.Template, tt.want[0])
+			}
+			if configs := pc.TargetConfigs(); len(configs) != len(tt.want) || configs[len(configs)-1].Template != tt.want[len(tt.want)-1] {
+				t.Errorf("TargetConfigs() = %d configs, want one per target", len(configs))
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/template/builtin"
+	"github.com/angelcodes95/contindex/internal/validation"
+)
+
+// TemplateFile is the template source inside a template directory
+const TemplateFile = "template.md"
+
+// BuiltinTemplateRoot is the directory of the built-in templates inside builtin.FS
+const BuiltinTemplateRoot = "templates"
+
+// BuiltinPartialRoot is the directory of the built-in partials inside builtin.FS
+const BuiltinPartialRoot = "partials"
+
+// ProjectTemplateDir holds the templates of one project, relative to the project root
+var ProjectTemplateDir = filepath.Join(".contindex", "templates")
+
+// ProjectPartialDir holds the partials of one project, relative to the project root
+var ProjectPartialDir = filepath.Join(".contindex", "partials")
+
+// MetadataFile describes a template next to its template.md
+const MetadataFile = "template.json"
+
+// DefaultReferenceSyntax references chapters by their path when a template does not say otherwise
+const DefaultReferenceSyntax = "%s"
+
+// UserTemplateDir returns the templates directory shared by all projects of
+// the user, or "" when the user config directory is unknown
+func UserTemplateDir() string {
+	return userDir("templates")
+}
+
+// UserPartialDir returns the partials directory shared by all projects of
+// the user, or "" when the user config directory is unknown
+func UserPartialDir() string {
+	return userDir("partials")
+}
+
+func userDir(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "contindex", name)
+}
+
+// TemplateDirs returns the directories searched for templates on disk,
+// highest priority first. Built-in templates are searched after them.
+func TemplateDirs(projectRoot string) []string {
+	dirs := []string{filepath.Join(projectRoot, ProjectTemplateDir)}
+	if userDir := UserTemplateDir(); userDir != "" {
+		dirs = append(dirs, userDir)
+	}
+	return dirs
+}
+
+// PartialDirs returns the directories searched for partials on disk, highest
+// priority first. Built-in partials are searched after them.
+func PartialDirs(projectRoot string) []string {
+	dirs := []string{filepath.Join(projectRoot, ProjectPartialDir)}
+	if userDir := UserPartialDir(); userDir != "" {
+		dirs = append(dirs, userDir)
+	}
+	return dirs
+}
+
+// BuiltinTemplates returns the names of the templates embedded in the binary
+func BuiltinTemplates() []string {
+	entries, err := builtin.FS.ReadDir(BuiltinTemplateRoot)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// TemplateConfigs discovers the templates available to a project. A template
+// in the project template directory overrides one of the same name in the
+// user template directory, which overrides a built-in template. Metadata in a
+// template.json is applied on top of the template it overrides, so an
+// override without one keeps the index file location of the template it
+// replaces; a new template without one writes NAME.md in upper case.
+func TemplateConfigs(projectRoot string) (map[string]TemplateConfig, error) {
+	configs := make(map[string]TemplateConfig)
+	for _, name := range BuiltinTemplates() {
+		templateConfig := TemplateConfig{Name: name, MainFile: defaultMainFile(name), ReferenceSyntax: DefaultReferenceSyntax}
+		metadataPath := path.Join(BuiltinTemplateRoot, name, MetadataFile)
+		if data, err := builtin.FS.ReadFile(metadataPath); err == nil {
+			if err := templateConfig.applyMetadata(data, "built-in "+metadataPath); err != nil {
+				return nil, err
+			}
+		}
+		configs[name] = templateConfig
+	}
+
+	// Apply the lowest priority directory first so higher ones win
+	metadata := make(map[string][]byte)
+	dirs := TemplateDirs(projectRoot)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		found, err := discoverTemplates(dirs[i])
+		if err != nil {
+			return nil, err
+		}
+		for name, dir := range found {
+			templateConfig, ok := configs[name]
+			if !ok {
+				templateConfig = TemplateConfig{Name: name, MainFile: defaultMainFile(name), ReferenceSyntax: DefaultReferenceSyntax}
+			}
+			templateConfig.Dir = dir
+
+			metadataPath := filepath.Join(dir, MetadataFile)
+			data, err := os.ReadFile(metadataPath)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to read template metadata %s: %w", metadataPath, err)
+			}
+			if err == nil {
+				if err := templateConfig.applyMetadata(data, metadataPath); err != nil {
+					return nil, err
+				}
+				if !isBuiltin(name) {
+					metadata[name] = data
+				}
+			}
+			configs[name] = templateConfig
+		}
+	}
+
+	for name := range metadata {
+		if err := inheritMetadata(configs, metadata, name, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
+
+// inheritMetadata bases a new template that extends another template on the
+// reference syntax, tools, token limits, chapter files and subdir of that template, then
+// applies its own template.json again so the fields it sets still win. The
+// index file name and description are never inherited. Templates that extend
+// a partial or, through a cycle, themselves are left alone.
+func inheritMetadata(configs map[string]TemplateConfig, metadata map[string][]byte, name string, seen map[string]bool) error {
+	templateConfig := configs[name]
+	parent, ok := configs[templateConfig.Extends]
+	if !ok || seen[name] {
+		return nil
+	}
+	seen[name] = true
+	if _, pending := metadata[parent.Name]; pending {
+		if err := inheritMetadata(configs, metadata, parent.Name, seen); err != nil {
+			return err
+		}
+		parent = configs[parent.Name]
+	}
+
+	inherited := TemplateConfig{
+		Name:            name,
+		MainFile:        defaultMainFile(name),
+		SubDir:          parent.SubDir,
+		ReferenceSyntax: parent.ReferenceSyntax,
+		Tools:           parent.Tools,
+		TokenLimits:     parent.TokenLimits,
+		ChapterFile:     parent.ChapterFile,
+		Dir:             templateConfig.Dir,
+	}
+	if err := inherited.applyMetadata(metadata[name], filepath.Join(templateConfig.Dir, MetadataFile)); err != nil {
+		return err
+	}
+	configs[name] = inherited
+	delete(metadata, name)
+	return nil
+}
+
+// applyMetadata sets the fields present in a template.json and checks the result
+func (tc *TemplateConfig) applyMetadata(data []byte, source string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(tc); err != nil {
+		return fmt.Errorf("invalid template metadata %s: %w", source, err)
+	}
+
+	switch {
+	case tc.MainFile == "" || filepath.Base(tc.MainFile) != tc.MainFile:
+		return fmt.Errorf("invalid template metadata %s: main_file must be a file name, got %q", source, tc.MainFile)
+	case filepath.IsAbs(tc.SubDir) || strings.HasPrefix(filepath.Clean(tc.SubDir), ".."):
+		return fmt.Errorf("invalid template metadata %s: subdir must stay inside the project, got %q", source, tc.SubDir)
+	case strings.Count(tc.ReferenceSyntax, "%s") != 1:
+		return fmt.Errorf("invalid template metadata %s: reference_syntax must contain %%s once, got %q", source, tc.ReferenceSyntax)
+	case tc.TokenLimits.Index < 0 || tc.TokenLimits.Chapter < 0:
+		return fmt.Errorf("invalid template metadata %s: token limits must not be negative", source)
+	case tc.ChapterFile != "" && (strings.Count(tc.ChapterFile, "%s") != 1 || filepath.Base(tc.ChapterFile) != tc.ChapterFile):
+		return fmt.Errorf("invalid template metadata %s: chapter_file must be a file name containing %%s once, got %q", source, tc.ChapterFile)
+	}
+	return nil
+}
+
+// SupportedTemplates returns the names of the templates available to a
+// project: the built-in templates, then templates only found on disk, each
+// in alphabetical order
+func SupportedTemplates(projectRoot string) ([]string, error) {
+	configs, err := TemplateConfigs(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	names := BuiltinTemplates()
+	var custom []string
+	for name := range configs {
+		if !isBuiltin(name) {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...), nil
+}
+
+// LookupTemplate returns the configuration of one template available to a project
+func LookupTemplate(template, projectRoot string) (TemplateConfig, error) {
+	configs, err := TemplateConfigs(projectRoot)
+	if err != nil {
+		return TemplateConfig{}, err
+	}
+	templateConfig, ok := configs[template]
+	if !ok {
+		return TemplateConfig{}, fmt.Errorf("%s: %s", ErrUnsupportedTemplate, template)
+	}
+	return templateConfig, nil
+}
+
+// discoverTemplates returns the template directories inside dir by name. A
+// template directory contains a template.md; other entries and directories
+// whose name is not a valid template name are ignored. A missing dir has no
+// templates.
+func discoverTemplates(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory %s: %w", dir, err)
+	}
+
+	found := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == AllTemplates || validation.ValidateTemplateName(name) != nil {
+			continue
+		}
+		templateDir := filepath.Join(dir, name)
+		if _, err := os.Stat(filepath.Join(templateDir, TemplateFile)); err != nil {
+			continue
+		}
+		found[name] = templateDir
+	}
+	return found, nil
+}
+
+func isBuiltin(name string) bool {
+	for _, builtinName := range BuiltinTemplates() {
+		if name == builtinName {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultMainFile names the index file of a template without a known location
+func defaultMainFile(name string) string {
+	return strings.ToUpper(name) + ".md"
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTemplate creates dir/name/template.md
+func writeTemplate(t *testing.T, dir, name string) string {
+	t.Helper()
+	templateDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, TemplateFile), []byte("# {{.ProjectName}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return templateDir
+}
+
+// writeMetadata writes the template.json of a template directory
+func writeMetadata(t *testing.T, templateDir, metadata string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(templateDir, MetadataFile), []byte(metadata), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateConfigs(t *testing.T) {
+	userConfig := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userConfig)
+	t.Setenv("HOME", userConfig)
+	userDir := UserTemplateDir()
+	root := t.TempDir()
+	projectDir := filepath.Join(root, ProjectTemplateDir)
+
+	userClaude := writeTemplate(t, userDir, "claude")
+	userTeam := writeTemplate(t, userDir, "team")
+	projectTeam := writeTemplate(t, projectDir, "team")
+	userCursor := writeTemplate(t, userDir, "cursor")
+	writeMetadata(t, userCursor, `{"description": "Team cursor", "main_file": "TEAM.md", "subdir": "docs", "token_limits": {"index": 900}}`)
+	writeTemplate(t, projectDir, "bad_name")
+	if err := os.MkdirAll(filepath.Join(projectDir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := TemplateConfigs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		wantMainFile    string
+		wantSubDir      string
+		wantDir         string
+		wantDescription string
+		wantSyntax      string
+		wantIndexLimit  int
+	}{
+		{"copilot", "copilot-instructions.md", ".github", "", "GitHub Copilot compatible with .github placement", "%s", 5000},
+		{"claude", "CLAUDE.md", "", userClaude, "Optimized for Claude Code with @context/ references", "@%s", 5000},
+		{"cursor", "TEAM.md", "docs", userCursor, "Team cursor", "@%s", 900},
+		{"team", "TEAM.md", "", projectTeam, "", DefaultReferenceSyntax, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := configs[tt.name]
+			if got.Name != tt.name || got.MainFile != tt.wantMainFile || got.SubDir != tt.wantSubDir || got.Dir != tt.wantDir {
+				t.Errorf("TemplateConfigs()[%s] = %+v, want main file %s in %q from %q", tt.name, got, tt.wantMainFile, tt.wantSubDir, tt.wantDir)
+			}
+			if got.Description != tt.wantDescription || got.ReferenceSyntax != tt.wantSyntax || got.TokenLimits.Index != tt.wantIndexLimit {
+				t.Errorf("TemplateConfigs()[%s] metadata = %+v", tt.name, got)
+			}
+		})
+	}
+	if configs["team"].Dir == userTeam {
+		t.Errorf("user template overrides the project template")
+	}
+
+	names, err := SupportedTemplates(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"claude", "copilot", "cursor", "cursor-rules", "gemini", "generic", "team"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("SupportedTemplates() = %v, want %v", names, want)
+	}
+
+	// Custom templates and cursor-rules are not part of "all"
+	targets, err := ExpandTemplates([]string{"team", AllTemplates}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"team", "claude", "copilot", "cursor", "gemini"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("ExpandTemplates() = %v, want %v", targets, want)
+	}
+}
+
+func TestExpandAllTemplates(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	projectDir := filepath.Join(root, ProjectTemplateDir)
+
+	writeTemplate(t, projectDir, "team")
+	writeMetadata(t, writeTemplate(t, projectDir, "docs"), `{"main_file": "DOCS.md", "in_all": true}`)
+	writeMetadata(t, writeTemplate(t, projectDir, "gemini"), `{"in_all": false}`)
+	writeMetadata(t, writeTemplate(t, projectDir, "generic"), `{"in_all": true}`)
+
+	targets, err := ExpandTemplates([]string{AllTemplates}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"claude", "copilot", "cursor", "generic", "docs"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("ExpandTemplates(all) = %v, want %v", targets, want)
+	}
+}
+
+func TestBuiltinTemplateMetadata(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configs, err := TemplateConfigs(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range BuiltinTemplates() {
+		templateConfig := configs[name]
+		if templateConfig.Description == "" || len(templateConfig.Tools) == 0 {
+			t.Errorf("built-in template %s has no description or tools in %s", name, MetadataFile)
+		}
+	}
+}
+
+func TestInvalidTemplateMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		wantErr  string
+	}{
+		{"malformed", `{"main_file": `, "invalid template metadata"},
+		{"unknown field", `{"mainfile": "X.md"}`, "unknown field"},
+		{"main file with directory", `{"main_file": "docs/X.md"}`, "main_file must be a file name"},
+		{"subdir outside project", `{"subdir": "../elsewhere"}`, "subdir must stay inside the project"},
+		{"reference without placeholder", `{"reference_syntax": "@context"}`, "reference_syntax must contain %s once"},
+		{"negative limit", `{"token_limits": {"index": -1}}`, "must not be negative"},
+		{"chapter file without placeholder", `{"chapter_file": "rule.mdc"}`, "chapter_file must be a file name"},
+		{"chapter file with directory", `{"chapter_file": "rules/%s.mdc"}`, "chapter_file must be a file name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			root := t.TempDir()
+			templateDir := writeTemplate(t, filepath.Join(root, ProjectTemplateDir), "custom")
+			writeMetadata(t, templateDir, tt.metadata)
+
+			_, err := TemplateConfigs(root)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("TemplateConfigs() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtendsInheritsMetadata(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	dir := filepath.Join(root, ProjectTemplateDir)
+	writeMetadata(t, writeTemplate(t, dir, "team"), `{"extends": "claude", "token_limits": {"index": 2000}}`)
+	writeMetadata(t, writeTemplate(t, dir, "squad"), `{"extends": "team"}`)
+	writeMetadata(t, writeTemplate(t, dir, "loop"), `{"extends": "loop"}`)
+
+	configs, err := TemplateConfigs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"team", "squad"} {
+		templateConfig := configs[name]
+		if templateConfig.ReferenceSyntax != "@%s" || templateConfig.TokenLimits.Index != 2000 {
+			t.Errorf("%s did not inherit metadata: %+v", name, templateConfig)
+		}
+		if templateConfig.MainFile != defaultMainFile(name) || templateConfig.Description != "" {
+			t.Errorf("%s inherited the index file or description: %+v", name, templateConfig)
+		}
+	}
+	if configs["loop"].ReferenceSyntax != DefaultReferenceSyntax {
+		t.Errorf("loop = %+v, want the defaults", configs["loop"])
+	}
+}
+
+func TestChapterFileName(t *testing.T) {
+	tc := TemplateConfig{ChapterFile: "%s.mdc"}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"auth.md", "auth.mdc"},
+		{"guides/setup.md", "guides-setup.mdc"},
+	}
+	for _, tt := range tests {
+		if got := tc.ChapterFileName(tt.path); got != tt.want {
+			t.Errorf("ChapterFileName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
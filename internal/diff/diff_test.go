@@ -0,0 +1,51 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{
+			name:    "identical",
+			oldText: "a\nb\n",
+			newText: "a\nb\n",
+			want:    "",
+		},
+		{
+			name:    "added line with context",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			newText: "1\n2\n3\n4\nnew\n5\n6\n7\n8\n",
+			want:    "--- old\n+++ new\n@@ -2,6 +2,7 @@\n 2\n 3\n 4\n+new\n 5\n 6\n 7\n",
+		},
+		{
+			name:    "separate hunks",
+			oldText: "a\n1\n2\n3\n4\n5\n6\n7\nb\n",
+			newText: "A\n1\n2\n3\n4\n5\n6\n7\nB\n",
+			want:    "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+		{
+			name:    "new file",
+			oldText: "",
+			newText: "x\n",
+			want:    "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			name:    "missing final newline",
+			oldText: "a\nb",
+			newText: "a\nb\n",
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.oldText, tt.newText, DefaultContext); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package frontmatter
+
+import "strings"
+
+// Chapter is the metadata recorded at the top of every chapter file
+type Chapter struct {
+	Title       string   // Original section heading
+	HeadingPath []string // Enclosing headings, outermost first
+	Source      string   // Monolithic file the chapter was converted from
+	StartLine   int      // First source line of the chapter
+	EndLine     int      // Last source line of the chapter
+	Part        int      // Part number when a section was split, 0 otherwise
+	Summary     string   // One-sentence summary for the index
+	KeyTerms    []string // Distinctive terms for the index
+	Globs       []string // File patterns the chapter applies to, for tools that scope rules by file
+	Tokens      int      // Token count of the chapter body
+	Tokenizer   string   // Tokenizer that produced Tokens
+}
+
+// Fields returns the chapter metadata in the order it is written
+func (c *Chapter) Fields() []Field {
+	fields := []Field{{Key: "title", Value: c.Title}}
+	if len(c.HeadingPath) > 0 {
+		fields = append(fields, Field{Key: "heading_path", Value: c.HeadingPath})
+	}
+	if c.Source != "" {
+		fields = append(fields,
+			Field{Key: "source", Value: c.Source},
+			Field{Key: "start_line", Value: c.StartLine},
+			Field{Key: "end_line", Value: c.EndLine})
+	}
+	if c.Part > 0 {
+		fields = append(fields, Field{Key: "part", Value: c.Part})
+	}
+	fields = append(fields,
+		Field{Key: "summary", Value: c.Summary},
+		Field{Key: "key_terms", Value: c.KeyTerms})
+	if len(c.Globs) > 0 {
+		fields = append(fields, Field{Key: "globs", Value: c.Globs})
+	}
+	fields = append(fields, Field{Key: "tokens", Value: c.Tokens})
+	if c.Tokenizer != "" {
+		fields = append(fields, Field{Key: "tokenizer", Value: c.Tokenizer})
+	}
+	return fields
+}
+
+// Format renders the chapter metadata as a front-matter block
+func (c *Chapter) Format() string {
+	return Format(c.Fields())
+}
+
+// ChapterFromValues reads chapter metadata from decoded front matter
+func ChapterFromValues(values Values) *Chapter {
+	return &Chapter{
+		Title:       values.String("title"),
+		HeadingPath: values.List("heading_path"),
+		Source:      values.String("source"),
+		StartLine:   values.Int("start_line"),
+		EndLine:     values.Int("end_line"),
+		Part:        values.Int("part"),
+		Summary:     values.String("summary"),
+		KeyTerms:    values.List("key_terms"),
+		Globs:       splitGlobs(values.List("globs")),
+		Tokens:      values.Int("tokens"),
+		Tokenizer:   values.String("tokenizer"),
+	}
+}
+
+// ParseChapter splits a chapter file into its metadata and body. Files
+// without front matter return nil metadata and the whole content as body.
+func ParseChapter(content string) (*Chapter, string, error) {
+	values, body, err := Split(content)
+	if err != nil || values == nil {
+		return nil, body, err
+	}
+	return ChapterFromValues(values), body, nil
+}
+
+// splitGlobs accepts globs as a list or, as Cursor writes them, as one
+// comma-separated scalar
+func splitGlobs(values []string) []string {
+	var globs []string
+	for _, value := range values {
+		for _, glob := range strings.Split(value, ",") {
+			if glob = strings.TrimSpace(glob); glob != "" {
+				globs = append(globs, glob)
+			}
+		}
+	}
+	return globs
+}
@@ -0,0 +1,336 @@
+package frontmatter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Delimiter opens and closes a front-matter block
+const Delimiter = "---"
+
+// Field is one key of a front-matter block. Values are strings, ints, bools or string lists.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Values holds decoded front-matter keys. Scalars decode to strings and
+// lists to string slices; the accessors convert them.
+type Values map[string]any
+
+// Format renders fields as a YAML front-matter block, in order. Strings are
+// always double-quoted so that no value is reinterpreted as another type.
+func Format(fields []Field) string {
+	var b strings.Builder
+	b.WriteString(Delimiter + "\n")
+	for _, field := range fields {
+		switch value := field.Value.(type) {
+		case []string:
+			if len(value) == 0 {
+				fmt.Fprintf(&b, "%s: []\n", field.Key)
+				continue
+			}
+			fmt.Fprintf(&b, "%s:\n", field.Key)
+			for _, item := range value {
+				fmt.Fprintf(&b, "  - %s\n", quote(item))
+			}
+		case string:
+			fmt.Fprintf(&b, "%s: %s\n", field.Key, quote(value))
+		case int:
+			fmt.Fprintf(&b, "%s: %d\n", field.Key, value)
+		case bool:
+			fmt.Fprintf(&b, "%s: %t\n", field.Key, value)
+		default:
+			fmt.Fprintf(&b, "%s: %s\n", field.Key, quote(fmt.Sprint(value)))
+		}
+	}
+	b.WriteString(Delimiter + "\n")
+	return b.String()
+}
+
+// Split separates a leading front-matter block from the rest of the content.
+// Content without a block is returned unchanged with nil values.
+func Split(content string) (Values, string, error) {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, Delimiter+"\n") {
+		return nil, content, nil
+	}
+
+	lines := strings.Split(normalized, "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if trimmed := strings.TrimRight(lines[i], " \t"); trimmed == Delimiter || trimmed == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, content, fmt.Errorf("front matter is not closed with %s", Delimiter)
+	}
+
+	values, err := Parse(lines[1:end])
+	if err != nil {
+		return nil, content, err
+	}
+	return values, strings.Join(lines[end+1:], "\n"), nil
+}
+
+// Parse decodes the lines between the delimiters. It accepts the subset of
+// YAML used by front matter: top-level scalar keys, block lists of scalars and
+// flow lists such as [a, "b"]. Unknown keys are kept; comments are ignored.
+func Parse(lines []string) (Values, error) {
+	values := make(Values)
+	var listKey string
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Items of a block list
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if listKey == "" || line[0] != ' ' && line[0] != '-' {
+				return nil, fmt.Errorf("front matter line %d: list item outside a list", i+1)
+			}
+			item, err := parseScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("front matter line %d: %w", i+1, err)
+			}
+			values[listKey] = append(values[listKey].([]string), item)
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("front matter line %d: unexpected indentation", i+1)
+		}
+
+		key, raw, found := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("front matter line %d: expected 'key: value'", i+1)
+		}
+		raw = strings.TrimSpace(raw)
+
+		listKey = ""
+		switch {
+		case raw == "":
+			// A block list may follow
+			values[key] = []string{}
+			listKey = key
+		case strings.HasPrefix(raw, "["):
+			list, err := parseFlowList(raw)
+			if err != nil {
+				return nil, fmt.Errorf("front matter line %d: %w", i+1, err)
+			}
+			values[key] = list
+		default:
+			value, err := parseScalar(raw)
+			if err != nil {
+				return nil, fmt.Errorf("front matter line %d: %w", i+1, err)
+			}
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
+// String returns a scalar value, or "" when the key is missing or a list
+func (v Values) String(key string) string {
+	value, _ := v[key].(string)
+	return value
+}
+
+// Int returns a scalar value as an integer, or 0 when it is not one
+func (v Values) Int(key string) int {
+	value, err := strconv.Atoi(v.String(key))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// Bool returns a scalar value as a boolean, or false when it is not one
+func (v Values) Bool(key string) bool {
+	value, err := strconv.ParseBool(v.String(key))
+	return err == nil && value
+}
+
+// List returns a list value. A scalar is returned as a one-item list.
+func (v Values) List(key string) []string {
+	switch value := v[key].(type) {
+	case []string:
+		return value
+	case string:
+		if value != "" {
+			return []string{value}
+		}
+	}
+	return nil
+}
+
+// quote renders a string as a double-quoted YAML scalar
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\x%02x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// parseScalar decodes a quoted or plain scalar
+func parseScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected text after string: %s", rest)
+		}
+		return unquoteDouble(raw[1:end])
+	case strings.HasPrefix(raw, "'"):
+		body := raw[1:]
+		var b strings.Builder
+		for i := 0; i < len(body); i++ {
+			if body[i] != '\'' {
+				b.WriteByte(body[i])
+				continue
+			}
+			if i+1 < len(body) && body[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), nil
+		}
+		return "", fmt.Errorf("unterminated string %s", raw)
+	default:
+		// Plain scalars end at a comment
+		if index := strings.Index(raw, " #"); index >= 0 {
+			raw = raw[:index]
+		}
+		raw = strings.TrimSpace(raw)
+		if raw == "~" || raw == "null" {
+			return "", nil
+		}
+		return raw, nil
+	}
+}
+
+// closingQuote returns the index of the quote that ends a double-quoted scalar
+func closingQuote(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteDouble(body string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			b.WriteByte(body[i])
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", fmt.Errorf("invalid escape at end of string")
+		}
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '/':
+			b.WriteByte(body[i])
+		case 'x':
+			if i+2 >= len(body) {
+				return "", fmt.Errorf("invalid \\x escape")
+			}
+			code, err := strconv.ParseUint(body[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid \\x escape: %w", err)
+			}
+			b.WriteByte(byte(code))
+			i += 2
+		default:
+			return "", fmt.Errorf("unsupported escape \\%c", body[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parseFlowList decodes a single-line list such as [a, "b, c"]
+func parseFlowList(raw string) ([]string, error) {
+	if !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("unterminated list %s", raw)
+	}
+	inner := strings.TrimSpace(raw[1 : len(raw)-1])
+	items := []string{}
+	for inner != "" {
+		var token string
+		switch inner[0] {
+		case '"':
+			end := closingQuote(inner)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in list %s", raw)
+			}
+			token, inner = inner[:end+1], inner[end+1:]
+		case '\'':
+			end := strings.Index(inner[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in list %s", raw)
+			}
+			token, inner = inner[:end+2], inner[end+2:]
+		default:
+			end := strings.Index(inner, ",")
+			if end < 0 {
+				end = len(inner)
+			}
+			token, inner = inner[:end], inner[end:]
+		}
+
+		item, err := parseScalar(strings.TrimSpace(token))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		inner = strings.TrimSpace(inner)
+		if inner != "" && inner[0] != ',' {
+			return nil, fmt.Errorf("expected ',' in list %s", raw)
+		}
+		inner = strings.TrimSpace(strings.TrimPrefix(inner, ","))
+	}
+	return items, nil
+}
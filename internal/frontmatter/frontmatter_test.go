@@ -0,0 +1,108 @@
+package frontmatter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChapterRoundTrip(t *testing.T) {
+	chapter := &Chapter{
+		Title:       `Auth: "tokens" & sessions`,
+		HeadingPath: []string{"API", "Security # notes"},
+		Source:      "CLAUDE.md",
+		StartLine:   12,
+		EndLine:     40,
+		Part:        2,
+		Summary:     "Tokens expire after one hour.\nRefresh them early.",
+		KeyTerms:    []string{"`make auth`", "refresh tokens", "true"},
+		Globs:       []string{"internal/auth/**", "cmd/login.go"},
+		Tokens:      321,
+		Tokenizer:   "cl100k_base",
+	}
+
+	content := chapter.Format() + "\n# Auth\n\nBody text.\n"
+	got, body, err := ParseChapter(content)
+	if err != nil {
+		t.Fatalf("ParseChapter() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, chapter) {
+		t.Errorf("ParseChapter() = %+v, want %+v", got, chapter)
+	}
+	if body != "\n# Auth\n\nBody text.\n" {
+		t.Errorf("ParseChapter() body = %q", body)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		want    Values
+		wantErr bool
+	}{
+		{
+			name:  "plain, quoted and flow values",
+			lines: []string{"title: Plain title # comment", "summary: 'it''s fine'", "globs: [src/**/*.ts, \"a, b\"]"},
+			want:  Values{"title": "Plain title", "summary": "it's fine", "globs": []string{"src/**/*.ts", "a, b"}},
+		},
+		{
+			name:  "block list and empty list",
+			lines: []string{"key_terms:", "  - one", "  - \"two\"", "heading_path: []"},
+			want:  Values{"key_terms": []string{"one", "two"}, "heading_path": []string{}},
+		},
+		{
+			name:    "missing colon",
+			lines:   []string{"just text"},
+			wantErr: true,
+		},
+		{
+			name:    "unterminated string",
+			lines:   []string{`title: "open`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.lines)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitWithoutFrontMatter(t *testing.T) {
+	content := "# Title\n\n---\n\nBody\n"
+	values, body, err := Split(content)
+	if err != nil || values != nil || body != content {
+		t.Errorf("Split() = %v, %q, %v, want content unchanged", values, body, err)
+	}
+}
+
+func TestChapterGlobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"list", "---\nglobs:\n  - src/**/*.ts\n  - README.md\n---\n", []string{"src/**/*.ts", "README.md"}},
+		{"comma-separated scalar", "---\nglobs: src/**/*.ts, README.md\n---\n", []string{"src/**/*.ts", "README.md"}},
+		{"empty", "---\nglobs:\n---\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chapter, _, err := ParseChapter(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(chapter.Globs, tt.want) {
+				t.Errorf("Globs = %q, want %q", chapter.Globs, tt.want)
+			}
+		})
+	}
+}
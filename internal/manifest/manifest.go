@@ -0,0 +1,196 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileName is the manifest location relative to the project root
+const FileName = ".contindex/manifest.json"
+
+// Version is the manifest format version written by this build
+const Version = 1
+
+// Chapter records the state of one chapter file
+type Chapter struct {
+	Path   string `json:"path"`   // Slash-separated path relative to the context dir
+	Hash   string `json:"sha256"` // Hash of the file bytes
+	Size   int64  `json:"size"`   // File size in bytes
+	Tokens int    `json:"tokens"` // Token count of the chapter body
+}
+
+// Index records the state of one index file written from the chapters
+type Index struct {
+	Path string `json:"path"`   // Slash-separated path relative to the project root
+	Hash string `json:"sha256"` // Hash of the file bytes as written
+}
+
+// Manifest records the chapters and index files produced by the last convert or update
+type Manifest struct {
+	Version    int       `json:"version"`
+	ContextDir string    `json:"context_dir"` // Context dir relative to the project root
+	Indexes    []Index   `json:"indexes"`
+	Tokenizer  string    `json:"tokenizer"`
+	Chapters   []Chapter `json:"chapters"`
+}
+
+// Rename is a chapter whose content moved to a new path
+type Rename struct {
+	From string
+	To   string
+}
+
+// Changes lists the chapter differences between two manifests
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Renamed  []Rename
+	Modified []string
+}
+
+// Load reads the manifest of a project. A missing manifest returns nil without error.
+func Load(projectRoot string) (*Manifest, error) {
+	path := filepath.Join(projectRoot, FileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("manifest %s has version %d, newer than supported version %d", path, m.Version, Version)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest under the project root with chapters sorted by path
+func (m *Manifest) Save(projectRoot string) error {
+	m.Version = Version
+	sort.Slice(m.Chapters, func(i, j int) bool {
+		return m.Chapters[i].Path < m.Chapters[j].Path
+	})
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	path := filepath.Join(projectRoot, FileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", path, err)
+	}
+	return nil
+}
+
+// IndexHash returns the recorded hash of an index file, or "" when the
+// manifest does not record it
+func (m *Manifest) IndexHash(path string) string {
+	for _, index := range m.Indexes {
+		if index.Path == path {
+			return index.Hash
+		}
+	}
+	return ""
+}
+
+// Hash returns the hex SHA-256 of data
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashFile returns the hex SHA-256 of a file, or "" when it does not exist
+func HashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return Hash(data), nil
+}
+
+// Diff compares the chapters of a previous manifest with the current ones. A
+// removed chapter whose hash reappears under a new path is reported as a rename.
+// A nil previous manifest reports every chapter as added.
+func Diff(previous, current *Manifest) Changes {
+	var changes Changes
+
+	before := make(map[string]Chapter)
+	if previous != nil {
+		for _, chapter := range previous.Chapters {
+			before[chapter.Path] = chapter
+		}
+	}
+	after := make(map[string]Chapter)
+	for _, chapter := range current.Chapters {
+		after[chapter.Path] = chapter
+	}
+
+	var added []Chapter
+	for _, chapter := range current.Chapters {
+		old, ok := before[chapter.Path]
+		switch {
+		case !ok:
+			added = append(added, chapter)
+		case old.Hash != chapter.Hash:
+			changes.Modified = append(changes.Modified, chapter.Path)
+		}
+	}
+
+	// Pair each removed chapter with an added chapter of the same content
+	removedByHash := make(map[string][]string)
+	if previous != nil {
+		for _, chapter := range previous.Chapters {
+			if _, ok := after[chapter.Path]; !ok {
+				removedByHash[chapter.Hash] = append(removedByHash[chapter.Hash], chapter.Path)
+			}
+		}
+	}
+	renamedFrom := make(map[string]bool)
+	for _, chapter := range added {
+		if candidates := removedByHash[chapter.Hash]; len(candidates) > 0 {
+			changes.Renamed = append(changes.Renamed, Rename{From: candidates[0], To: chapter.Path})
+			renamedFrom[candidates[0]] = true
+			removedByHash[chapter.Hash] = candidates[1:]
+			continue
+		}
+		changes.Added = append(changes.Added, chapter.Path)
+	}
+
+	if previous != nil {
+		for _, chapter := range previous.Chapters {
+			if _, ok := after[chapter.Path]; !ok && !renamedFrom[chapter.Path] {
+				changes.Removed = append(changes.Removed, chapter.Path)
+			}
+		}
+	}
+
+	return changes
+}
+
+// Empty reports whether no chapter changed
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Renamed) == 0 && len(c.Modified) == 0
+}
+
+// Summary returns a one-line count of the changes
+func (c Changes) Summary() string {
+	return fmt.Sprintf("%d added, %d removed, %d renamed, %d modified",
+		len(c.Added), len(c.Removed), len(c.Renamed), len(c.Modified))
+}
@@ -0,0 +1,70 @@
+package manifest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	previous := &Manifest{Chapters: []Chapter{
+		{Path: "auth.md", Hash: "a"},
+		{Path: "db.md", Hash: "b"},
+		{Path: "old-name.md", Hash: "c"},
+		{Path: "gone.md", Hash: "d"},
+	}}
+	current := &Manifest{Chapters: []Chapter{
+		{Path: "auth.md", Hash: "a"},
+		{Path: "db.md", Hash: "b2"},
+		{Path: "api/new-name.md", Hash: "c"},
+		{Path: "fresh.md", Hash: "e"},
+	}}
+
+	want := Changes{
+		Added:    []string{"fresh.md"},
+		Removed:  []string{"gone.md"},
+		Renamed:  []Rename{{From: "old-name.md", To: "api/new-name.md"}},
+		Modified: []string{"db.md"},
+	}
+	if got := Diff(previous, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	if got := Diff(current, current); !got.Empty() {
+		t.Errorf("Diff() of identical manifests = %+v, want no changes", got)
+	}
+
+	if got := Diff(nil, current); len(got.Added) != len(current.Chapters) {
+		t.Errorf("Diff(nil) added = %v, want every chapter", got.Added)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	root := t.TempDir()
+	if m, err := Load(root); m != nil || err != nil {
+		t.Fatalf("Load() of missing manifest = %v, %v, want nil, nil", m, err)
+	}
+
+	saved := &Manifest{
+		ContextDir: "context",
+		Indexes:    []Index{{Path: "CLAUDE.md", Hash: Hash([]byte("index"))}},
+		Tokenizer:  "heuristic",
+		Chapters: []Chapter{
+			{Path: "b.md", Hash: Hash([]byte("b")), Size: 1, Tokens: 0},
+			{Path: "a.md", Hash: Hash([]byte("a")), Size: 1, Tokens: 0},
+		},
+	}
+	if err := saved.Save(root); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("Load() = %+v, want %+v", loaded, saved)
+	}
+	if loaded.Chapters[0].Path != "a.md" {
+		t.Errorf("chapters not sorted by path: %v", loaded.Chapters)
+	}
+}
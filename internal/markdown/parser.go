@@ -0,0 +1,532 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BlockKind identifies the type of a top-level Markdown block
+type BlockKind int
+
+const (
+	BlockParagraph BlockKind = iota
+	BlockHeading
+	BlockFencedCode
+	BlockIndentedCode
+	BlockHTML
+	BlockQuote
+	BlockList
+	BlockThematicBreak
+)
+
+// String returns a readable name for the block kind
+func (k BlockKind) String() string {
+	switch k {
+	case BlockParagraph:
+		return "paragraph"
+	case BlockHeading:
+		return "heading"
+	case BlockFencedCode:
+		return "fenced-code"
+	case BlockIndentedCode:
+		return "indented-code"
+	case BlockHTML:
+		return "html"
+	case BlockQuote:
+		return "block-quote"
+	case BlockList:
+		return "list"
+	case BlockThematicBreak:
+		return "thematic-break"
+	default:
+		return "unknown"
+	}
+}
+
+// Block is a top-level Markdown block with its location in the source
+type Block struct {
+	Kind      BlockKind
+	Level     int    // Heading level (1-6), zero for other blocks
+	Text      string // Heading text without markers or closing hashes
+	Setext    bool   // True for headings underlined with === or ---
+	StartLine int    // First source line of the block (1-based)
+	EndLine   int    // Last source line of the block (1-based, inclusive)
+}
+
+// Regular expression patterns for block recognition
+var (
+	atxHeadingPattern    = regexp.MustCompile(`^#{1,6}(?:[ \t]|$)`)
+	setextPattern        = regexp.MustCompile(`^(=+|-+)[ \t]*$`)
+	bulletItemPattern    = regexp.MustCompile(`^([-+*])([ \t]+|$)`)
+	orderedItemPattern   = regexp.MustCompile(`^([0-9]{1,9})([.)])([ \t]+|$)`)
+	htmlRawTagPattern    = regexp.MustCompile(`(?i)^<(script|pre|style|textarea)(?:[ \t>]|$)`)
+	htmlBlockTagPattern  = regexp.MustCompile(`(?i)^</?([a-z][a-z0-9]*)(?:[ \t>]|/>|$)`)
+	htmlCompleteTagRegex = regexp.MustCompile(`(?i)^(?:<[a-z][a-z0-9-]*(?:\s+[a-z_:][a-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|</[a-z][a-z0-9-]*\s*>)[ \t]*$`)
+)
+
+// htmlBlockTags lists the tag names that start a CommonMark type 6 HTML block
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "base": true, "basefont": true,
+	"blockquote": true, "body": true, "caption": true, "center": true, "col": true,
+	"colgroup": true, "dd": true, "details": true, "dialog": true, "dir": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "frame": true, "frameset": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"head": true, "header": true, "hr": true, "html": true, "iframe": true,
+	"legend": true, "li": true, "link": true, "main": true, "menu": true,
+	"menuitem": true, "nav": true, "noframes": true, "ol": true, "optgroup": true,
+	"option": true, "p": true, "param": true, "search": true, "section": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "tfoot": true,
+	"th": true, "thead": true, "title": true, "tr": true, "track": true, "ul": true,
+}
+
+// SplitLines splits source text into lines, dropping line terminators
+func SplitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	if source == "" {
+		return nil
+	}
+	lines := strings.Split(source, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Parse splits Markdown source into its top-level blocks
+func Parse(source string) []*Block {
+	p := &parser{lines: SplitLines(source)}
+	p.run()
+	return p.blocks
+}
+
+// Headings returns only the heading blocks from a parsed document
+func Headings(blocks []*Block) []*Block {
+	var headings []*Block
+	for _, block := range blocks {
+		if block.Kind == BlockHeading {
+			headings = append(headings, block)
+		}
+	}
+	return headings
+}
+
+// parser holds the state of a single parse
+type parser struct {
+	lines  []string
+	pos    int
+	blocks []*Block
+}
+
+func (p *parser) run() {
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if isBlank(line) {
+			p.pos++
+			continue
+		}
+
+		indent, rest := splitIndent(line)
+		if indent >= 4 {
+			p.indentedCode()
+			continue
+		}
+
+		switch {
+		case isFenceOpener(rest):
+			p.fencedCode(indent, rest)
+		case atxHeadingPattern.MatchString(rest):
+			p.atxHeading(rest)
+		case isThematicBreak(rest):
+			p.emit(&Block{Kind: BlockThematicBreak}, p.pos, p.pos)
+			p.pos++
+		case strings.HasPrefix(rest, ">"):
+			p.blockQuote()
+		case htmlStartType(rest, false) != 0:
+			p.htmlBlock(htmlStartType(rest, false))
+		case isListItem(rest, false):
+			p.list()
+		default:
+			p.paragraph()
+		}
+	}
+}
+
+// emit records a block spanning the given zero-based line indexes
+func (p *parser) emit(block *Block, start, end int) {
+	block.StartLine = start + 1
+	block.EndLine = end + 1
+	p.blocks = append(p.blocks, block)
+}
+
+func (p *parser) atxHeading(rest string) {
+	marker := 0
+	for marker < len(rest) && rest[marker] == '#' {
+		marker++
+	}
+
+	text := strings.TrimSpace(rest[marker:])
+
+	// Strip an optional closing sequence of hashes preceded by whitespace
+	trimmed := strings.TrimRight(text, "#")
+	if trimmed == "" {
+		text = ""
+	} else if len(trimmed) < len(text) && (strings.HasSuffix(trimmed, " ") || strings.HasSuffix(trimmed, "\t")) {
+		text = strings.TrimSpace(trimmed)
+	}
+
+	p.emit(&Block{Kind: BlockHeading, Level: marker, Text: text}, p.pos, p.pos)
+	p.pos++
+}
+
+func (p *parser) fencedCode(indent int, rest string) {
+	fenceChar := rest[0]
+	fenceLen := 0
+	for fenceLen < len(rest) && rest[fenceLen] == fenceChar {
+		fenceLen++
+	}
+
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.lines) {
+		if isFenceCloser(p.lines[p.pos], fenceChar, fenceLen) {
+			p.emit(&Block{Kind: BlockFencedCode}, start, p.pos)
+			p.pos++
+			return
+		}
+		p.pos++
+	}
+
+	// An unclosed fence runs to the end of the document
+	p.emit(&Block{Kind: BlockFencedCode}, start, len(p.lines)-1)
+}
+
+func (p *parser) indentedCode() {
+	start := p.pos
+	end := p.pos
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if isBlank(line) {
+			p.pos++
+			continue
+		}
+		if indent, _ := splitIndent(line); indent < 4 {
+			break
+		}
+		end = p.pos
+		p.pos++
+	}
+
+	// Trailing blank lines are not part of the code block
+	p.pos = end + 1
+	p.emit(&Block{Kind: BlockIndentedCode}, start, end)
+}
+
+func (p *parser) blockQuote() {
+	start := p.pos
+	lazyAllowed := false
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if isBlank(line) {
+			break
+		}
+
+		indent, rest := splitIndent(line)
+		if indent < 4 && strings.HasPrefix(rest, ">") {
+			inner := strings.TrimSpace(strings.TrimPrefix(rest, ">"))
+			lazyAllowed = inner != "" && !isFenceOpener(inner)
+			p.pos++
+			continue
+		}
+
+		// Lazy continuation lines extend a quoted paragraph
+		if !lazyAllowed || interruptsParagraph(line) {
+			break
+		}
+		p.pos++
+	}
+	p.emit(&Block{Kind: BlockQuote}, start, p.pos-1)
+}
+
+func (p *parser) htmlBlock(kind int) {
+	start := p.pos
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if kind >= 6 {
+			if isBlank(line) {
+				break
+			}
+		} else if htmlBlockEnds(kind, line) {
+			p.pos++
+			break
+		}
+		p.pos++
+	}
+	p.emit(&Block{Kind: BlockHTML}, start, p.pos-1)
+}
+
+func (p *parser) list() {
+	start := p.pos
+	end := p.pos
+	contentIndent := listContentIndent(p.lines[p.pos])
+	p.pos++
+
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+
+		if isBlank(line) {
+			// A list continues past blank lines only into indented content or another item
+			next := p.pos + 1
+			for next < len(p.lines) && isBlank(p.lines[next]) {
+				next++
+			}
+			if next >= len(p.lines) {
+				break
+			}
+			indent, rest := splitIndent(p.lines[next])
+			if indent < contentIndent && !(indent < 4 && isListItem(rest, false)) {
+				break
+			}
+			p.pos = next
+			continue
+		}
+
+		indent, rest := splitIndent(line)
+		switch {
+		case indent < 4 && isListItem(rest, false) && !isThematicBreak(rest):
+			contentIndent = listContentIndent(line)
+		case indent >= contentIndent:
+			if isFenceOpener(rest) {
+				p.listFence(indent, rest)
+				end = p.pos - 1
+				continue
+			}
+		case interruptsParagraph(line) || (p.pos > 0 && isBlank(p.lines[p.pos-1])):
+			p.pos = end + 1
+			p.emit(&Block{Kind: BlockList}, start, end)
+			return
+		}
+
+		end = p.pos
+		p.pos++
+	}
+
+	p.pos = end + 1
+	p.emit(&Block{Kind: BlockList}, start, end)
+}
+
+// listFence consumes a fenced code block nested inside a list item
+func (p *parser) listFence(indent int, rest string) {
+	fenceChar := rest[0]
+	fenceLen := 0
+	for fenceLen < len(rest) && rest[fenceLen] == fenceChar {
+		fenceLen++
+	}
+
+	p.pos++
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		lineIndent, lineRest := splitIndent(line)
+		if !isBlank(line) && lineIndent < indent {
+			// Content dedented out of the list item closes the fence
+			return
+		}
+		p.pos++
+		if lineIndent-indent < 4 && isFenceCloser(lineRest, fenceChar, fenceLen) {
+			return
+		}
+	}
+}
+
+func (p *parser) paragraph() {
+	start := p.pos
+	p.pos++
+
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if isBlank(line) {
+			break
+		}
+
+		indent, rest := splitIndent(line)
+		if indent < 4 && setextPattern.MatchString(rest) {
+			var text []string
+			for _, paragraphLine := range p.lines[start:p.pos] {
+				text = append(text, strings.TrimSpace(paragraphLine))
+			}
+			level := 2
+			if rest[0] == '=' {
+				level = 1
+			}
+			p.emit(&Block{Kind: BlockHeading, Level: level, Text: strings.Join(text, " "), Setext: true}, start, p.pos)
+			p.pos++
+			return
+		}
+
+		if interruptsParagraph(line) {
+			break
+		}
+		p.pos++
+	}
+
+	p.emit(&Block{Kind: BlockParagraph}, start, p.pos-1)
+}
+
+// interruptsParagraph reports whether a line starts a block that can interrupt a paragraph
+func interruptsParagraph(line string) bool {
+	indent, rest := splitIndent(line)
+	if indent >= 4 {
+		return false
+	}
+	return isFenceOpener(rest) ||
+		atxHeadingPattern.MatchString(rest) ||
+		isThematicBreak(rest) ||
+		strings.HasPrefix(rest, ">") ||
+		htmlStartType(rest, true) != 0 ||
+		isListItem(rest, true)
+}
+
+// splitIndent returns the column width of leading whitespace and the remaining text
+func splitIndent(line string) (int, string) {
+	width := 0
+	for i, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width, line[i:]
+		}
+	}
+	return width, ""
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isFenceOpener(rest string) bool {
+	if len(rest) < 3 || (rest[0] != '`' && rest[0] != '~') {
+		return false
+	}
+	fenceChar := rest[0]
+	n := 0
+	for n < len(rest) && rest[n] == fenceChar {
+		n++
+	}
+	if n < 3 {
+		return false
+	}
+	// Backtick fences cannot carry backticks in their info string
+	return fenceChar != '`' || !strings.Contains(rest[n:], "`")
+}
+
+func isFenceCloser(line string, fenceChar byte, fenceLen int) bool {
+	indent, rest := splitIndent(line)
+	if indent >= 4 {
+		return false
+	}
+	n := 0
+	for n < len(rest) && rest[n] == fenceChar {
+		n++
+	}
+	return n >= fenceLen && strings.TrimSpace(rest[n:]) == ""
+}
+
+func isThematicBreak(rest string) bool {
+	if rest == "" || (rest[0] != '-' && rest[0] != '*' && rest[0] != '_') {
+		return false
+	}
+	marker := rest[0]
+	count := 0
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case marker:
+			count++
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+// isListItem reports whether text starts a list item; items interrupting a
+// paragraph must not be empty and ordered ones must start at 1
+func isListItem(rest string, interrupting bool) bool {
+	if m := bulletItemPattern.FindStringSubmatch(rest); m != nil {
+		return !interrupting || strings.TrimSpace(rest[len(m[0]):]) != ""
+	}
+	if m := orderedItemPattern.FindStringSubmatch(rest); m != nil {
+		if !interrupting {
+			return true
+		}
+		return m[1] == "1" && strings.TrimSpace(rest[len(m[0]):]) != ""
+	}
+	return false
+}
+
+// listContentIndent returns the column where a list item's content begins
+func listContentIndent(line string) int {
+	indent, rest := splitIndent(line)
+	marker := bulletItemPattern.FindString(rest)
+	if marker == "" {
+		marker = orderedItemPattern.FindString(rest)
+	}
+	markerWidth := len(strings.TrimRight(marker, " \t"))
+	spacing := len(marker) - markerWidth
+	if spacing < 1 || spacing > 4 {
+		spacing = 1
+	}
+	return indent + markerWidth + spacing
+}
+
+// htmlStartType returns the CommonMark HTML block type (1-7) started by text, or 0
+func htmlStartType(rest string, interrupting bool) int {
+	if !strings.HasPrefix(rest, "<") {
+		return 0
+	}
+	switch {
+	case htmlRawTagPattern.MatchString(rest):
+		return 1
+	case strings.HasPrefix(rest, "<!--"):
+		return 2
+	case strings.HasPrefix(rest, "<?"):
+		return 3
+	case strings.HasPrefix(rest, "<![CDATA["):
+		return 5
+	case len(rest) > 2 && rest[1] == '!' && isASCIILetter(rest[2]):
+		return 4
+	}
+	if m := htmlBlockTagPattern.FindStringSubmatch(rest); m != nil && htmlBlockTags[strings.ToLower(m[1])] {
+		return 6
+	}
+	if !interrupting && htmlCompleteTagRegex.MatchString(rest) {
+		return 7
+	}
+	return 0
+}
+
+// htmlBlockEnds reports whether a line satisfies the end condition of HTML block types 1-5
+func htmlBlockEnds(kind int, line string) bool {
+	lower := strings.ToLower(line)
+	switch kind {
+	case 1:
+		return strings.Contains(lower, "</script>") || strings.Contains(lower, "</pre>") ||
+			strings.Contains(lower, "</style>") || strings.Contains(lower, "</textarea>")
+	case 2:
+		return strings.Contains(line, "-->")
+	case 3:
+		return strings.Contains(line, "?>")
+	case 4:
+		return strings.Contains(line, ">")
+	case 5:
+		return strings.Contains(line, "]]>")
+	}
+	return false
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,128 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseHeadings(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string // "level:text@line"
+	}{
+		{
+			name:   "atx headings",
+			source: "# Title\n\n## Setup\n\ntext\n\n### Details\n",
+			want:   []string{"1:Title@1", "2:Setup@3", "3:Details@7"},
+		},
+		{
+			name:   "closing hashes are stripped",
+			source: "## Install ##\n### Keep #hash\n## C# ##",
+			want:   []string{"2:Install@1", "3:Keep #hash@2", "2:C#@3"},
+		},
+		{
+			name:   "hash without space is not a heading",
+			source: "##nope\n#5 bolt\n## yes",
+			want:   []string{"2:yes@3"},
+		},
+		{
+			name:   "fenced code hides shell comments",
+			source: "## Setup\n\n```bash\n## install deps\nnpm install\n```\n\n## Next\n",
+			want:   []string{"2:Setup@1", "2:Next@8"},
+		},
+		{
+			name:   "tilde fence with longer closer",
+			source: "~~~~\n## inside\n~~~\n## still inside\n~~~~~\n## Outside",
+			want:   []string{"2:Outside@6"},
+		},
+		{
+			name:   "unclosed fence runs to end",
+			source: "## Real\n```\n## fake\n",
+			want:   []string{"2:Real@1"},
+		},
+		{
+			name:   "indented code is not a heading",
+			source: "## Real\n\n    ## indented\n",
+			want:   []string{"2:Real@1"},
+		},
+		{
+			name:   "html block hides headings",
+			source: "<div>\n## inside html\n</div>\n\n## After\n",
+			want:   []string{"2:After@5"},
+		},
+		{
+			name:   "html comment spanning lines",
+			source: "<!--\n## commented out\n-->\n## Visible",
+			want:   []string{"2:Visible@4"},
+		},
+		{
+			name:   "block quote hides headings",
+			source: "> ## quoted\n> more\n\n## After",
+			want:   []string{"2:After@4"},
+		},
+		{
+			name:   "setext headings",
+			source: "Project Title\n=============\n\nSetup Guide\n---\n\ntext\n",
+			want:   []string{"1:Project Title@1", "2:Setup Guide@4"},
+		},
+		{
+			name:   "thematic break after blank line is not setext",
+			source: "Paragraph\n\n---\n\n## Next",
+			want:   []string{"2:Next@5"},
+		},
+		{
+			name:   "dashes after list are a thematic break",
+			source: "- item one\n- item two\n---\n## Next",
+			want:   []string{"2:Next@4"},
+		},
+		{
+			name:   "fence nested in list item",
+			source: "1. Install:\n   ```bash\n   ## comment\n   ```\n2. Run\n\n## Next",
+			want:   []string{"2:Next@7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, h := range Headings(Parse(tt.source)) {
+				got = append(got, formatHeading(h))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Parse() headings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlockRanges(t *testing.T) {
+	source := "# Title\n\nIntro text\nmore intro\n\n```go\nfunc main() {}\n```\n\n    indented\n    code\n\n- a\n- b\n"
+	blocks := Parse(source)
+
+	want := []struct {
+		kind       BlockKind
+		start, end int
+	}{
+		{BlockHeading, 1, 1},
+		{BlockParagraph, 3, 4},
+		{BlockFencedCode, 6, 8},
+		{BlockIndentedCode, 10, 11},
+		{BlockList, 13, 14},
+	}
+
+	if len(blocks) != len(want) {
+		t.Fatalf("Parse() returned %d blocks, want %d", len(blocks), len(want))
+	}
+	for i, w := range want {
+		b := blocks[i]
+		if b.Kind != w.kind || b.StartLine != w.start || b.EndLine != w.end {
+			t.Errorf("block %d = %s %d-%d, want %s %d-%d", i, b.Kind, b.StartLine, b.EndLine, w.kind, w.start, w.end)
+		}
+	}
+}
+
+func formatHeading(b *Block) string {
+	return fmt.Sprintf("%d:%s@%d", b.Level, b.Text, b.StartLine)
+}
@@ -0,0 +1,11 @@
+// Package builtin embeds the templates that ship with contindex. It has no
+// dependencies so that config can discover them without importing template.
+package builtin
+
+import "embed"
+
+// FS holds one directory per built-in template under templates/ and the
+// shared definitions every template can use under partials/
+//
+//go:embed templates/* partials/*
+var FS embed.FS
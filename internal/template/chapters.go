@@ -0,0 +1,41 @@
+package template
+
+// Chapter describes one chapter file for templates
+type Chapter struct {
+	Name     string   // Descriptive name, the file name without .md
+	Title    string   // Original section heading
+	Path     string   // Slash-separated path relative to the context directory
+	Group    string   // Subdirectory of the context directory, empty at the top level
+	Summary  string   // One-sentence summary
+	KeyTerms []string // Distinctive terms
+	Tokens   int      // Token count of the chapter body
+	Globs    []string // File patterns the chapter applies to
+	Content  string   // Chapter body without its front matter and title
+}
+
+// ChapterGroup is one entry of the chapter list: a top-level chapter, or a
+// subdirectory with the chapters inside it
+type ChapterGroup struct {
+	Name     string // Subdirectory name, empty for a top-level chapter
+	Chapters []Chapter
+}
+
+// GroupChapters groups chapters by subdirectory in the order they first
+// appear. Each top-level chapter is a group of its own.
+func GroupChapters(chapters []Chapter) []ChapterGroup {
+	var groups []ChapterGroup
+	index := make(map[string]int)
+	for _, chapter := range chapters {
+		if chapter.Group == "" {
+			groups = append(groups, ChapterGroup{Chapters: []Chapter{chapter}})
+			continue
+		}
+		if i, ok := index[chapter.Group]; ok {
+			groups[i].Chapters = append(groups[i].Chapters, chapter)
+			continue
+		}
+		index[chapter.Group] = len(groups)
+		groups = append(groups, ChapterGroup{Name: chapter.Group, Chapters: []Chapter{chapter}})
+	}
+	return groups
+}
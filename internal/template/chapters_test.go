@@ -0,0 +1,226 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/angelcodes95/contindex/internal/config"
+)
+
+func TestGroupChapters(t *testing.T) {
+	chapters := []Chapter{
+		{Name: "overview"},
+		{Name: "login", Group: "auth"},
+		{Name: "schema"},
+		{Name: "tokens", Group: "auth"},
+	}
+
+	var got [][]string
+	for _, group := range GroupChapters(chapters) {
+		names := []string{group.Name}
+		for _, chapter := range group.Chapters {
+			names = append(names, chapter.Name)
+		}
+		got = append(got, names)
+	}
+
+	want := [][]string{{"", "overview"}, {"auth", "login", "tokens"}, {"", "schema"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupChapters() = %v, want %v", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		text string
+		want string
+	}{
+		{"short", 10, "hello", "hello"},
+		{"exact", 5, "hello", "hello"},
+		{"cut", 8, "hello world", "hello..."},
+		{"trailing space", 9, "hello world", "hello..."},
+		{"tiny", 2, "hello", "he"},
+		{"multibyte", 4, "héllo", "h..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.max, tt.text); got != tt.want {
+				t.Errorf("truncate(%d, %q) = %q, want %q", tt.max, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChapterList(t *testing.T) {
+	data := &Data{
+		ContextDir: "context",
+		Chapters: []Chapter{
+			{Name: "overview", Path: "overview.md", Summary: "What the project does.", KeyTerms: []string{"cli", "index"}},
+			{Name: "login", Path: "auth/login.md", Group: "auth", KeyTerms: []string{"session"}},
+			{Name: "tokens", Path: "auth/tokens.md", Group: "auth", Summary: "Token refresh."},
+		},
+	}
+
+	got, err := New().Execute(`{{template "chapter-list" .}}`, t.TempDir(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "1. **overview** - `context/overview.md` - What the project does.\n" +
+		"   Key terms: cli, index\n" +
+		"2. **auth/** - `context/auth/`\n" +
+		"   1. **login** - `context/auth/login.md`\n" +
+		"      Key terms: session\n" +
+		"   2. **tokens** - `context/auth/tokens.md` - Token refresh."
+	if got != want {
+		t.Errorf("chapter list =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		data    *Data
+		want    string
+	}{
+		{
+			name:    "heuristic tokens",
+			content: `{{range .Chapters}}{{tokens .Tokens}}{{end}}`,
+			data:    &Data{Chapters: []Chapter{{Tokens: 120}}},
+			want:    "~120 tokens",
+		},
+		{
+			name:    "exact tokens",
+			content: `{{range .Chapters}}{{tokens .Tokens}}{{end}}`,
+			data:    &Data{Tokenizer: "cl100k_base", Chapters: []Chapter{{Tokens: 120}}},
+			want:    "120 tokens",
+		},
+		{
+			name:    "ref and summary",
+			content: `{{range .Chapters}}{{ref .Path}}: {{.Summary | truncate 8}}{{end}}`,
+			data:    &Data{ContextDir: "docs/context", Chapters: []Chapter{{Path: "api.md", Summary: "Endpoints and errors"}}},
+			want:    "docs/context/api.md: Endpo...",
+		},
+		{
+			name:    "ref in reference syntax",
+			content: `{{range .Chapters}}{{ref .Path}}{{end}}`,
+			data:    &Data{ContextDir: "context", ReferenceSyntax: "@%s", Chapters: []Chapter{{Path: "auth/login.md"}}},
+			want:    "@context/auth/login.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().Execute(tt.content, t.TempDir(), tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderReferenceSyntax(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	chapters := []Chapter{{Name: "setup", Path: "setup.md", Summary: "Install the tools."}}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"claude", "1. **setup** - `@context/setup.md` - Install the tools."},
+		{"copilot", "1. **setup** - `context/setup.md` - Install the tools."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			projectConfig, err := config.Load(root, config.Settings{Template: config.TemplateList{tt.template}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rendered, err := New().Render(projectConfig, chapters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(rendered, tt.want) {
+				t.Errorf("Render() does not list the chapter as %q:\n%s", tt.want, rendered)
+			}
+		})
+	}
+}
+
+func TestRenderChapter(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	projectConfig, err := config.Load(root, config.Settings{Template: config.TemplateList{"cursor-rules"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		chapter Chapter
+		want    string
+	}{
+		{
+			name: "summary and globs",
+			chapter: Chapter{
+				Name:    "auth",
+				Title:   "Authentication",
+				Path:    "auth.md",
+				Summary: `Sessions use "signed" cookies.`,
+				Globs:   []string{"internal/auth/**", "main.go"},
+				Content: "Sessions expire after an hour.",
+			},
+			want: `---
+description: "Sessions use \"signed\" cookies."
+globs: internal/auth/**,main.go
+alwaysApply: false
+---
+# Authentication
+
+Sessions expire after an hour.
+`,
+		},
+		{
+			// Cursor only attaches a rule by its globs or description
+			name:    "no summary or globs falls back to the title",
+			chapter: Chapter{Name: "notes", Title: "Release Notes", Path: "notes.md"},
+			want: `---
+description: "Release Notes"
+globs:
+alwaysApply: false
+---
+# Release Notes
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().RenderChapter(projectConfig, []Chapter{tt.chapter}, tt.chapter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderChapter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Templates without a chapter-file definition cannot render chapter files
+	projectConfig, err = config.Load(root, config.Settings{Template: config.TemplateList{"claude"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New().RenderChapter(projectConfig, nil, tests[0].chapter); err == nil {
+		t.Errorf("RenderChapter() with the claude template succeeded")
+	}
+}
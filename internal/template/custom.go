@@ -0,0 +1,115 @@
+package template
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/template/builtin"
+	"github.com/angelcodes95/contindex/internal/tokens"
+	"github.com/angelcodes95/contindex/internal/validation"
+)
+
+// SampleData returns made-up project data for previewing and validating templates
+func SampleData(templateName string) *Data {
+	return &Data{
+		ProjectName:      "sample-project",
+		ProjectRoot:      "/path/to/project",
+		ContextDir:       "context",
+		Template:         templateName,
+		GeneratedAt:      "2024-01-01 12:00:00",
+		ContindexVersion: Version,
+		ReferenceSyntax:  config.DefaultReferenceSyntax,
+		Tokenizer:        tokens.Default,
+		Chapters: []Chapter{
+			{Name: "sample-file-1", Path: "sample-file-1.md", Summary: "Example descriptively named file", KeyTerms: []string{"example"}, Tokens: 420,
+				Globs: []string{"src/example/**"}, Content: "Example content that mentions `src/example/`."},
+			{Name: "sample-file-2", Path: "sample-file-2.md", Summary: "Another example file with semantic naming", Tokens: 310},
+			{Name: "sample-file-3", Path: "guides/sample-file-3.md", Group: "guides", Summary: "Third example showing file-based organization", Tokens: 275},
+		},
+	}
+}
+
+// ExportTemplate copies the files of a built-in template into dir/name so it
+// can be edited as a template on disk. An existing template is only replaced
+// when force is set. It returns the path of the written template file.
+func (m *Manager) ExportTemplate(builtinName, name, dir string, force bool) (string, error) {
+	source := path.Join(config.BuiltinTemplateRoot, builtinName)
+	if _, err := builtin.FS.ReadFile(builtinTemplatePath(builtinName)); err != nil {
+		return "", fmt.Errorf("no built-in template named %s", builtinName)
+	}
+	if err := validation.ValidateTemplateName(name); err != nil {
+		return "", err
+	}
+	if name == config.AllTemplates {
+		return "", fmt.Errorf("template name %s is reserved", config.AllTemplates)
+	}
+
+	templateDir := filepath.Join(dir, name)
+	templateFile := filepath.Join(templateDir, config.TemplateFile)
+	if _, err := os.Stat(templateFile); err == nil && !force {
+		return "", fmt.Errorf("template already exists: %s\nUse --force to overwrite it", templateFile)
+	}
+
+	err := fs.WalkDir(builtin.FS, source, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(templateDir, filepath.FromSlash(strings.TrimPrefix(filePath, source)))
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		content, err := builtin.FS.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0644)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to export template %s: %v", builtinName, err)
+	}
+	return templateFile, nil
+}
+
+// ValidateTemplate checks that a template parses, renders with and without
+// chapters, and keeps the chapter list in a managed region update can refresh.
+// Templates that write a file per chapter must also render one.
+func (m *Manager) ValidateTemplate(templateName, projectRoot string) error {
+	info, err := m.GetTemplateInfo(templateName, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	sample := SampleData(templateName)
+	for _, chapters := range [][]Chapter{nil, sample.Chapters} {
+		data := SampleData(templateName)
+		data.ReferenceSyntax = info.ReferenceSyntax
+		data.Chapters = chapters
+
+		rendered, err := m.ExecuteTemplate(templateName, projectRoot, data)
+		if err != nil {
+			return err
+		}
+
+		body, err := ExtractRegion(rendered, ChaptersRegion)
+		if err != nil {
+			return fmt.Errorf("%v\nPlace the chapter list between %s and %s", err, RegionBegin(ChaptersRegion), RegionEnd(ChaptersRegion))
+		}
+		if len(chapters) > 0 && body == "" {
+			return fmt.Errorf("the chapters region renders nothing; use {{template \"chapter-list\" .}} or {{range .Chapters}}")
+		}
+	}
+
+	if info.ChapterFile == "" {
+		return nil
+	}
+	data := SampleData(templateName)
+	data.ReferenceSyntax = info.ReferenceSyntax
+	data.Chapter = &data.Chapters[0]
+	_, err = m.executeChapterFile(templateName, projectRoot, data)
+	return err
+}
@@ -0,0 +1,112 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angelcodes95/contindex/internal/config"
+)
+
+func TestExportTemplate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	dir := filepath.Join(root, config.ProjectTemplateDir)
+	m := New()
+
+	templateFile, err := m.ExportTemplate("claude", "team", dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "team", config.TemplateFile); templateFile != want {
+		t.Errorf("ExportTemplate() = %s, want %s", templateFile, want)
+	}
+	if _, err := m.ExportTemplate("claude", "team", dir, false); err == nil {
+		t.Errorf("ExportTemplate() overwrote an existing template without force")
+	}
+	if _, err := m.ExportTemplate("claude", "team", dir, true); err != nil {
+		t.Errorf("ExportTemplate() with force: %v", err)
+	}
+	if _, err := m.ExportTemplate("nope", "nope", dir, false); err == nil {
+		t.Errorf("ExportTemplate() exported a template that is not built in")
+	}
+
+	// The exported template is discovered and renders like the built-in one
+	if err := m.ValidateTemplate("team", root); err != nil {
+		t.Errorf("ValidateTemplate() of exported template: %v", err)
+	}
+	projectConfig, err := config.Load(root, config.Settings{Template: config.TemplateList{"team"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := m.Render(projectConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rendered, "Context Index") {
+		t.Errorf("Render() of exported template = %q", rendered)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "chapter list",
+			content: "# {{.ProjectName}}\n" + RegionBegin(ChaptersRegion) + "\n{{template \"chapter-list\" .}}\n" + RegionEnd(ChaptersRegion) + "\n",
+		},
+		{
+			name:    "range over chapters",
+			content: RegionBegin(ChaptersRegion) + "\n{{range .Chapters}}- {{ref .Path}}\n{{end}}" + RegionEnd(ChaptersRegion) + "\n",
+		},
+		{
+			name:    "parse error",
+			content: "{{.ProjectName",
+			wantErr: "failed to parse template",
+		},
+		{
+			name:    "unknown field",
+			content: "{{range .Categories}}{{end}}",
+			wantErr: "Categories",
+		},
+		{
+			name:    "missing region",
+			content: "{{template \"chapter-list\" .}}",
+			wantErr: "managed region not found",
+		},
+		{
+			name:    "empty region",
+			content: RegionBegin(ChaptersRegion) + "\n" + RegionEnd(ChaptersRegion),
+			wantErr: "renders nothing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			root := t.TempDir()
+			templateDir := filepath.Join(root, config.ProjectTemplateDir, "custom")
+			if err := os.MkdirAll(templateDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(templateDir, config.TemplateFile), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := New().ValidateTemplate("custom", root)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateTemplate() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateTemplate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
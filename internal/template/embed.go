@@ -1,6 +0,0 @@
-package template
-
-import "embed"
-
-//go:embed templates/*
-var TemplateFS embed.FS
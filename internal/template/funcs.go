@@ -0,0 +1,49 @@
+package template
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/angelcodes95/contindex/internal/tokens"
+)
+
+// FuncMap returns the helper functions available to templates:
+//
+//	tokens N         "~120 tokens", without the ~ for exact tokenizers
+//	join LIST SEP    strings.Join
+//	ref PATH         PATH inside the context directory in the reference syntax of the template, e.g. "@context/auth.md"
+//	truncate N TEXT  TEXT cut to N characters at a word with "..."; use as {{.Summary | truncate 80}}
+//	groups CHAPTERS  chapters grouped by subdirectory, see GroupChapters
+//	add A B          A + B, for 1-based numbering
+func FuncMap(data *Data) template.FuncMap {
+	return template.FuncMap{
+		"tokens": func(count int) string {
+			if tokens.Exact(data.Tokenizer) {
+				return fmt.Sprintf("%d tokens", count)
+			}
+			return fmt.Sprintf("~%d tokens", count)
+		},
+		"join": func(list []string, separator string) string {
+			return strings.Join(list, separator)
+		},
+		"ref": func(chapterPath string) string {
+			ref := path.Join(data.ContextDir, chapterPath)
+			if data.ReferenceSyntax == "" {
+				return ref
+			}
+			return fmt.Sprintf(data.ReferenceSyntax, ref)
+		},
+		"truncate": truncate,
+		"groups":   GroupChapters,
+		"add": func(a, b int) int {
+			return a + b
+		},
+	}
+}
+
+// truncate is TruncateRunes with the length first, so a template can pipe text into it
+func truncate(maxRunes int, text string) string {
+	return tokens.TruncateRunes(text, maxRunes)
+}
@@ -0,0 +1,132 @@
+package template
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/template/builtin"
+)
+
+// partial is one file of shared template definitions
+type partial struct {
+	name    string // Path shown in errors
+	content string
+}
+
+// newTemplateSet creates the template every index is rendered with: the
+// helper functions and every partial available to the project. Built-in
+// partials are parsed first, then user partials, then project partials, so a
+// definition on disk replaces a built-in definition of the same name.
+func (m *Manager) newTemplateSet(projectRoot string, data *Data) (*template.Template, error) {
+	tmpl := template.New("context")
+	funcs := FuncMap(data)
+	funcs["include"] = includeFunc(tmpl)
+	tmpl.Funcs(funcs)
+
+	partials, err := readPartials(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range partials {
+		// Each file gets its own template so stray text outside definitions
+		// cannot become the index body
+		if _, err := tmpl.New(p.name).Parse(p.content); err != nil {
+			return nil, fmt.Errorf("failed to parse partial %s: %v", p.name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// parseTemplateChain parses the template a template extends before the
+// template itself. Definitions parsed later replace earlier ones, and text
+// outside definitions replaces the body, so a template that extends another
+// only needs to define the sections it changes.
+func (m *Manager) parseTemplateChain(tmpl *template.Template, templateName, projectRoot string, seen map[string]bool) error {
+	if seen[templateName] {
+		return fmt.Errorf("template %s extends itself", templateName)
+	}
+	seen[templateName] = true
+
+	templateConfig, err := config.LookupTemplate(templateName, projectRoot)
+	if err != nil {
+		return err
+	}
+	content, err := m.getTemplateContent(templateName, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	if parent := templateConfig.Extends; parent != "" {
+		if _, err := config.LookupTemplate(parent, projectRoot); err == nil {
+			if err := m.parseTemplateChain(tmpl, parent, projectRoot, seen); err != nil {
+				return err
+			}
+		} else if tmpl.Lookup(parent) != nil {
+			// A layout partial becomes the body
+			if _, err := tmpl.Parse(fmt.Sprintf("{{template %q .}}", parent)); err != nil {
+				return fmt.Errorf("failed to parse template %s: %v", templateName, err)
+			}
+		} else {
+			return fmt.Errorf("template %s extends %s, which is neither a template nor a partial", templateName, parent)
+		}
+	}
+
+	if _, err := tmpl.Parse(content); err != nil {
+		return fmt.Errorf("failed to parse template %s: %v", templateName, err)
+	}
+	return nil
+}
+
+// readPartials returns the .md files of the built-in, user and project
+// partial directories, lowest priority first
+func readPartials(projectRoot string) ([]partial, error) {
+	var partials []partial
+
+	builtinFiles, err := fs.Glob(builtin.FS, path.Join(config.BuiltinPartialRoot, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range builtinFiles {
+		content, err := builtin.FS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partial %s: %v", file, err)
+		}
+		partials = append(partials, partial{name: "built-in " + file, content: string(content)})
+	}
+
+	dirs := config.PartialDirs(projectRoot)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		files, err := filepath.Glob(filepath.Join(dirs[i], "*.md"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read partial %s: %v", file, err)
+			}
+			partials = append(partials, partial{name: file, content: string(content)})
+		}
+	}
+	return partials, nil
+}
+
+// includeFunc returns the include helper, which renders a definition of tmpl
+// to a string so templates can test or transform the result
+func includeFunc(tmpl *template.Template) func(string, interface{}) (string, error) {
+	return func(name string, data interface{}) (string, error) {
+		var result strings.Builder
+		if err := tmpl.ExecuteTemplate(&result, name, data); err != nil {
+			return "", err
+		}
+		return result.String(), nil
+	}
+}
@@ -0,0 +1,135 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angelcodes95/contindex/internal/config"
+)
+
+// writeFile creates a file below root with its parent directories
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	file := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectPartialOverridesBuiltin(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFile(t, root, filepath.Join(config.ProjectPartialDir, "footer.md"), `{{define "footer"}}Maintained by the docs team{{end}}`)
+
+	rendered, err := New().ExecuteTemplate("claude", root, SampleData("claude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(rendered, "Maintained by the docs team\n") {
+		t.Errorf("ExecuteTemplate() did not use the project footer:\n%s", rendered)
+	}
+	if strings.Contains(rendered, "Generated by contindex") {
+		t.Errorf("ExecuteTemplate() kept the built-in footer")
+	}
+}
+
+func TestUserPartialPriority(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	root := t.TempDir()
+	writeFile(t, configHome, filepath.Join("contindex", "partials", "title.md"), `{{define "title"}}User title{{end}}{{define "intro"}}User intro{{end}}`)
+	writeFile(t, root, filepath.Join(config.ProjectPartialDir, "title.md"), `{{define "title"}}Project title{{end}}`)
+
+	rendered, err := New().ExecuteTemplate("generic", root, SampleData("generic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rendered, "# Project title\n") {
+		t.Errorf("project partial did not override the user partial:\n%s", rendered)
+	}
+	// The generic template defines its own intro, which wins over partials
+	if strings.Contains(rendered, "User intro") {
+		t.Errorf("user partial overrode a definition of the template")
+	}
+}
+
+func TestTemplateExtends(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		content  string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "extends built-in template",
+			metadata: `{"extends": "claude"}`,
+			content:  `{{define "intro"}}Team intro.{{end}}`,
+			want:     "Team intro.",
+		},
+		{
+			name:     "extends layout partial",
+			metadata: `{"extends": "base"}`,
+			content:  `{{define "tool"}}Team Bot{{end}}`,
+			want:     "## Contindex Workflow for Team Bot",
+		},
+		{
+			name:     "unknown parent",
+			metadata: `{"extends": "missing"}`,
+			content:  `{{define "intro"}}Team intro.{{end}}`,
+			wantErr:  "neither a template nor a partial",
+		},
+		{
+			name:     "extends itself",
+			metadata: `{"extends": "team"}`,
+			content:  `{{define "intro"}}Team intro.{{end}}`,
+			wantErr:  "extends itself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			root := t.TempDir()
+			dir := filepath.Join(config.ProjectTemplateDir, "team")
+			writeFile(t, root, filepath.Join(dir, config.MetadataFile), tt.metadata)
+			writeFile(t, root, filepath.Join(dir, config.TemplateFile), tt.content)
+
+			rendered, err := New().ExecuteTemplate("team", root, SampleData("team"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExecuteTemplate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(rendered, tt.want) {
+				t.Errorf("ExecuteTemplate() = %q, want it to contain %q", rendered, tt.want)
+			}
+			if _, err := ExtractRegion(rendered, ChaptersRegion); err != nil {
+				t.Errorf("ExecuteTemplate() lost the chapters region: %v", err)
+			}
+		})
+	}
+}
+
+func TestInclude(t *testing.T) {
+	content := `{{define "empty"}} {{end}}{{define "name"}}{{.ProjectName}}{{end}}` +
+		`{{with include "empty" .}}empty{{end}}[{{include "name" . | printf "%q"}}]`
+
+	got, err := New().Execute(content, t.TempDir(), SampleData("generic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Whitespace is kept, so only a truly empty result skips the section
+	if want := `empty["sample-project"]`; got != want {
+		t.Errorf("Execute() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ChaptersRegion is the managed region holding the chapter list of an index file
+const ChaptersRegion = "chapters"
+
+// ChaptersPlaceholder is the text a fresh index shows before any chapters exist
+const ChaptersPlaceholder = "(Chapter files will be listed here when you run `contindex update` or `contindex convert`)"
+
+// ErrRegionNotFound is returned when a file has no markers for a managed region
+var ErrRegionNotFound = errors.New("managed region not found")
+
+// chapterSectionHeadings are the chapter list headings used by the built-in
+// templates, recognized when migrating an index written without markers
+var chapterSectionHeadings = []string{
+	"## Available Chapters",
+	"## Available Chapter Files",
+	"## Available Context Chapters",
+	"## Available Context Files",
+}
+
+// RegionBegin returns the comment that opens a managed region
+func RegionBegin(name string) string {
+	return "<!-- contindex:" + name + ":begin -->"
+}
+
+// RegionEnd returns the comment that closes a managed region
+func RegionEnd(name string) string {
+	return "<!-- contindex:" + name + ":end -->"
+}
+
+// HasRegion reports whether content contains the markers of a managed region
+func HasRegion(content, name string) bool {
+	_, _, err := findRegion(content, name)
+	return err == nil
+}
+
+// ReplaceRegion replaces the text between the markers of a managed region.
+// The markers and everything outside them are kept byte for byte.
+func ReplaceRegion(content, name, body string) (string, error) {
+	start, end, err := findRegion(content, name)
+	if err != nil {
+		return "", err
+	}
+
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+	body = strings.TrimSpace(body)
+	if body != "" {
+		body = strings.ReplaceAll(body, "\n", newline) + newline
+	}
+
+	return content[:start] + newline + body + content[end:], nil
+}
+
+// ExtractRegion returns the text between the markers of a managed region,
+// without surrounding blank lines
+func ExtractRegion(content, name string) (string, error) {
+	start, end, err := findRegion(content, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(content[start:end]), nil
+}
+
+// findRegion returns the offsets just after the begin marker and at the start
+// of the end marker
+func findRegion(content, name string) (int, int, error) {
+	begin, finish := RegionBegin(name), RegionEnd(name)
+
+	start := strings.Index(content, begin)
+	if start < 0 {
+		return 0, 0, fmt.Errorf("%w: %s", ErrRegionNotFound, begin)
+	}
+	if strings.Count(content, begin) > 1 {
+		return 0, 0, fmt.Errorf("managed region '%s' is opened more than once", name)
+	}
+	start += len(begin)
+
+	end := strings.Index(content[start:], finish)
+	if end < 0 {
+		return 0, 0, fmt.Errorf("managed region '%s' is not closed: add %s after the generated content", name, finish)
+	}
+	return start, start + end, nil
+}
+
+// MigrateChaptersRegion adds chapter region markers to an index written
+// before managed regions existed. The placeholder or the body of the chapter
+// list section is wrapped; failing both, a new section is appended. It
+// returns the migrated content and a description of what was wrapped.
+func MigrateChaptersRegion(content string) (string, string) {
+	begin, finish := RegionBegin(ChaptersRegion), RegionEnd(ChaptersRegion)
+
+	if strings.Contains(content, ChaptersPlaceholder) {
+		wrapped := begin + "\n" + ChaptersPlaceholder + "\n" + finish
+		return strings.Replace(content, ChaptersPlaceholder, wrapped, 1), "wrapped the chapter placeholder"
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		heading := strings.TrimSpace(line)
+		if !isChapterSectionHeading(heading) {
+			continue
+		}
+
+		// The section runs until the next heading of any level
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if strings.HasPrefix(lines[j], "#") {
+				end = j
+				break
+			}
+		}
+		body := strings.TrimSpace(strings.Join(lines[i+1:end], ""))
+
+		var section strings.Builder
+		section.WriteString("\n" + begin + "\n")
+		if body != "" {
+			section.WriteString(body + "\n")
+		}
+		section.WriteString(finish + "\n")
+		if end < len(lines) {
+			section.WriteString("\n")
+		}
+
+		migrated := strings.Join(lines[:i+1], "") + section.String() + strings.Join(lines[end:], "")
+		return migrated, fmt.Sprintf("wrapped the list under '%s'", heading)
+	}
+
+	separator := "\n"
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		separator = "\n\n"
+	}
+	return content + separator + "## Available Chapters\n\n" + begin + "\n" + finish + "\n", "appended an Available Chapters section"
+}
+
+func isChapterSectionHeading(line string) bool {
+	for _, heading := range chapterSectionHeadings {
+		if strings.EqualFold(line, heading) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/angelcodes95/contindex/internal/config"
+)
+
+func TestReplaceRegion(t *testing.T) {
+	begin, end := RegionBegin(ChaptersRegion), RegionEnd(ChaptersRegion)
+
+	tests := []struct {
+		name    string
+		content string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "outside content is kept byte for byte",
+			content: "# Index\n\nTeam rules  \n\n" + begin + "\nold list\n" + end + "\n\nFooter",
+			body:    "1. new\n2. list\n",
+			want:    "# Index\n\nTeam rules  \n\n" + begin + "\n1. new\n2. list\n" + end + "\n\nFooter",
+		},
+		{
+			name:    "windows line endings",
+			content: "x\r\n" + begin + "\r\n" + end + "\r\n",
+			body:    "a\nb",
+			want:    "x\r\n" + begin + "\r\na\r\nb\r\n" + end + "\r\n",
+		},
+		{
+			name:    "empty body",
+			content: begin + "\nold\n" + end,
+			want:    begin + "\n" + end,
+		},
+		{
+			name:    "missing markers",
+			content: "# Index\n",
+			wantErr: true,
+		},
+		{
+			name:    "unclosed region",
+			content: begin + "\nold\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReplaceRegion(tt.content, ChaptersRegion, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReplaceRegion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReplaceRegion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateChaptersRegion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "placeholder",
+			content: "## Available Chapters\n\n" + ChaptersPlaceholder + "\n\n## Next\n",
+			want:    "## Available Chapters\n\n" + RegionBegin(ChaptersRegion) + "\n" + ChaptersPlaceholder + "\n" + RegionEnd(ChaptersRegion) + "\n\n## Next\n",
+		},
+		{
+			name:    "generated list under a known heading",
+			content: "Rules\n## Available Chapters\n\n1. **a** - `context/a.md`\n\n## Next\nkept\n",
+			want:    "Rules\n## Available Chapters\n\n" + RegionBegin(ChaptersRegion) + "\n1. **a** - `context/a.md`\n" + RegionEnd(ChaptersRegion) + "\n\n## Next\nkept\n",
+		},
+		{
+			name:    "no chapter section",
+			content: "# Custom index",
+			want:    "# Custom index\n\n## Available Chapters\n\n" + RegionBegin(ChaptersRegion) + "\n" + RegionEnd(ChaptersRegion) + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := MigrateChaptersRegion(tt.content)
+			if got != tt.want {
+				t.Errorf("MigrateChaptersRegion() = %q, want %q", got, tt.want)
+			}
+			if !HasRegion(got, ChaptersRegion) {
+				t.Errorf("migrated content has no region: %q", got)
+			}
+		})
+	}
+}
+
+func TestTemplatesHaveChaptersRegion(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range config.BuiltinTemplates() {
+		// Without chapters the region renders the placeholder
+		rendered, err := New().ExecuteTemplate(name, root, &Data{})
+		if err != nil {
+			t.Fatalf("template %s: %v", name, err)
+		}
+		body, err := ExtractRegion(rendered, ChaptersRegion)
+		if err != nil || body != ChaptersPlaceholder {
+			t.Errorf("template %s has no chapters region around the placeholder", name)
+		}
+		if !strings.HasSuffix(rendered, "*\n") || strings.HasSuffix(rendered, "\n\n") {
+			t.Errorf("template %s does not end with its footer and one newline: %q", name, rendered[len(rendered)-20:])
+		}
+	}
+}
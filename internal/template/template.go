@@ -1,15 +1,24 @@
 package template
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
 
 	"github.com/angelcodes95/contindex/internal/config"
+	"github.com/angelcodes95/contindex/internal/template/builtin"
+	"github.com/angelcodes95/contindex/internal/tokens"
 )
 
+// Version is the contindex version rendered into index files. The CLI sets
+// it to its own version at startup.
+var Version = "dev"
+
 // Manager handles template operations
 type Manager struct{}
 
@@ -27,58 +36,178 @@ type Data struct {
 	GeneratedAt      string
 	ContindexVersion string
 	ReferenceSyntax  string
+	Tokenizer        string    // Tokenizer that counted chapter tokens
+	Chapters         []Chapter // Chapters in index order, empty before any exist
+	Chapter          *Chapter  // Chapter rendered into its own file, nil for the index
 }
 
+// ChapterFileTemplate is the definition rendered for each chapter by
+// templates that write a file per chapter
+const ChapterFileTemplate = "chapter-file"
+
 // ApplyTemplate creates the main context file using the specified template
-func (m *Manager) ApplyTemplate(projectConfig *config.ProjectConfig) error {
+func (m *Manager) ApplyTemplate(projectConfig *config.ProjectConfig, chapters []Chapter) error {
+	content, err := m.Render(projectConfig, chapters)
+	if err != nil {
+		return err
+	}
+
+	// Create main context file
+	return m.writeContextFile(projectConfig.MainFile, content)
+}
+
+// Render returns the main context file content without writing it
+func (m *Manager) Render(projectConfig *config.ProjectConfig, chapters []Chapter) (string, error) {
 	// Prepare template data
 	templateData, err := m.prepareTemplateData(projectConfig)
 	if err != nil {
-		return fmt.Errorf("failed to prepare template data: %v", err)
+		return "", fmt.Errorf("failed to prepare template data: %v", err)
 	}
+	templateData.Chapters = chapters
 
-	// Get template content
-	templateContent, err := m.getTemplateContent(projectConfig.Template)
+	return m.ExecuteTemplate(projectConfig.Template, projectConfig.ProjectRoot, templateData)
+}
+
+// RenderChapter returns the content of the file the template writes for one
+// chapter, rendered from its "chapter-file" definition
+func (m *Manager) RenderChapter(projectConfig *config.ProjectConfig, chapters []Chapter, chapter Chapter) (string, error) {
+	templateData, err := m.prepareTemplateData(projectConfig)
 	if err != nil {
-		return fmt.Errorf("failed to get template content: %v", err)
+		return "", fmt.Errorf("failed to prepare template data: %v", err)
 	}
+	templateData.Chapters = chapters
+	templateData.Chapter = &chapter
+
+	return m.executeChapterFile(projectConfig.Template, projectConfig.ProjectRoot, templateData)
+}
 
-	// Parse and execute template
-	tmpl, err := template.New("context").Parse(templateContent)
+// executeChapterFile renders the "chapter-file" definition of a template for data.Chapter
+func (m *Manager) executeChapterFile(templateName, projectRoot string, data *Data) (string, error) {
+	tmpl, err := m.parseTemplate(templateName, projectRoot, data)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %v", err)
+		return "", err
+	}
+	if tmpl.Lookup(ChapterFileTemplate) == nil {
+		return "", fmt.Errorf("template %s defines no %q to render chapter files with", templateName, ChapterFileTemplate)
 	}
 
-	// Create main context file
-	return m.writeContextFile(projectConfig.MainFile, tmpl, templateData)
+	var result strings.Builder
+	if err := tmpl.ExecuteTemplate(&result, ChapterFileTemplate, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s: %v", ChapterFileTemplate, err)
+	}
+	return result.String(), nil
+}
+
+// RenderRegion renders the template and returns the body of one managed
+// region. Templates without the region fall back to the shared chapter list.
+func (m *Manager) RenderRegion(projectConfig *config.ProjectConfig, chapters []Chapter, name string) (string, error) {
+	content, err := m.Render(projectConfig, chapters)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := ExtractRegion(content, name)
+	if errors.Is(err, ErrRegionNotFound) && name == ChaptersRegion {
+		templateData, err := m.prepareTemplateData(projectConfig)
+		if err != nil {
+			return "", fmt.Errorf("failed to prepare template data: %v", err)
+		}
+		templateData.Chapters = chapters
+		return m.Execute(`{{template "chapter-list" .}}`, projectConfig.ProjectRoot, templateData)
+	}
+	return body, err
+}
+
+// Execute parses template content together with the partials available to
+// the project, and executes it with data
+func (m *Manager) Execute(content, projectRoot string, data *Data) (string, error) {
+	tmpl, err := m.newTemplateSet(projectRoot, data)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmpl.Parse(content); err != nil {
+		return "", fmt.Errorf("failed to parse template: %v", err)
+	}
+	return execute(tmpl, data)
+}
+
+// ExecuteTemplate renders a template available to the project with data,
+// after the templates and partials it extends
+func (m *Manager) ExecuteTemplate(templateName, projectRoot string, data *Data) (string, error) {
+	tmpl, err := m.parseTemplate(templateName, projectRoot, data)
+	if err != nil {
+		return "", err
+	}
+	return execute(tmpl, data)
+}
+
+// parseTemplate parses a template available to the project with the
+// partials and templates it extends
+func (m *Manager) parseTemplate(templateName, projectRoot string, data *Data) (*template.Template, error) {
+	tmpl, err := m.newTemplateSet(projectRoot, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.parseTemplateChain(tmpl, templateName, projectRoot, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func execute(tmpl *template.Template, data *Data) (string, error) {
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %v", err)
+	}
+	return result.String(), nil
 }
 
 // prepareTemplateData creates the data structure for template rendering
 func (m *Manager) prepareTemplateData(projectConfig *config.ProjectConfig) (*Data, error) {
-	projectName := filepath.Base(projectConfig.ProjectRoot)
+	projectName := projectConfig.ProjectName
+	if projectName == "" {
+		projectName = filepath.Base(projectConfig.ProjectRoot)
+	}
+
+	tokenizer := projectConfig.Tokenizer
+	if tokenizer == "" {
+		tokenizer = tokens.Default
+	}
 
-	// Determine reference syntax based on template
-	referenceSyntax := "@context/%s/"
-	if projectConfig.Template == "cursor" {
-		referenceSyntax = "context/%s/"
+	templateConfig, err := config.LookupTemplate(projectConfig.Template, projectConfig.ProjectRoot)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Data{
 		ProjectName:      projectName,
 		ProjectRoot:      projectConfig.ProjectRoot,
-		ContextDir:       projectConfig.ContextDir,
+		ContextDir:       projectConfig.RelativeContextDir(),
 		Template:         projectConfig.Template,
 		GeneratedAt:      time.Now().Format("2006-01-02 15:04:05"),
-		ContindexVersion: "0.0.3", // This should match the version in root.go
-		ReferenceSyntax:  referenceSyntax,
+		ContindexVersion: Version,
+		ReferenceSyntax:  templateConfig.ReferenceSyntax,
+		Tokenizer:        tokenizer,
 	}, nil
 }
 
-// getTemplateContent retrieves the template content for the specified template type
-func (m *Manager) getTemplateContent(templateType string) (string, error) {
-	templatePath := fmt.Sprintf("templates/%s/template.md", templateType)
+// getTemplateContent retrieves the template content for the specified
+// template type, preferring templates on disk over the built-in ones
+func (m *Manager) getTemplateContent(templateType, projectRoot string) (string, error) {
+	templateConfig, err := config.LookupTemplate(templateType, projectRoot)
+	if err != nil {
+		return "", err
+	}
+
+	if !templateConfig.Builtin() {
+		content, err := os.ReadFile(filepath.Join(templateConfig.Dir, config.TemplateFile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read template %s: %v", templateType, err)
+		}
+		return string(content), nil
+	}
 
-	content, err := TemplateFS.ReadFile(templatePath)
+	content, err := builtin.FS.ReadFile(builtinTemplatePath(templateType))
 	if err != nil {
 		return "", fmt.Errorf("template not found: %s", templateType)
 	}
@@ -86,77 +215,51 @@ func (m *Manager) getTemplateContent(templateType string) (string, error) {
 	return string(content), nil
 }
 
+// builtinTemplatePath returns the path of a built-in template inside builtin.FS
+func builtinTemplatePath(templateType string) string {
+	return path.Join(config.BuiltinTemplateRoot, templateType, config.TemplateFile)
+}
+
 // writeContextFile writes the rendered template to the main context file
-func (m *Manager) writeContextFile(filePath string, tmpl *template.Template, data *Data) error {
+func (m *Manager) writeContextFile(filePath string, content string) error {
 	// Ensure the parent directory exists
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return fmt.Errorf("failed to create parent directory: %v", err)
 	}
 
-	// Create the file
-	file, err := os.Create(filePath)
-	if err != nil {
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to create context file: %v", err)
 	}
-	defer file.Close()
-
-	// Execute template and write to file
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template: %v", err)
-	}
 
 	return nil
 }
 
-// ListTemplates returns available template names
-func (m *Manager) ListTemplates() []string {
-	return config.SupportedTemplates
+// ListTemplates returns the names of the templates available to a project
+func (m *Manager) ListTemplates(projectRoot string) ([]string, error) {
+	return config.SupportedTemplates(projectRoot)
 }
 
 // GetTemplateInfo returns detailed information about a template
-func (m *Manager) GetTemplateInfo(templateName string) (*Info, error) {
-	if err := config.ValidateTemplate(templateName); err != nil {
+func (m *Manager) GetTemplateInfo(templateName, projectRoot string) (*Info, error) {
+	templateConfig, err := config.LookupTemplate(templateName, projectRoot)
+	if err != nil {
 		return nil, err
 	}
 
-	templateConfig := config.TemplateConfigs[templateName]
-
 	// Read template content for preview
-	content, err := m.getTemplateContent(templateName)
+	content, err := m.getTemplateContent(templateName, projectRoot)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Info{
-		Name:        templateName,
-		Description: getTemplateDescription(templateName),
-		MainFile:    templateConfig.MainFile,
-		SubDir:      templateConfig.SubDir,
-		Content:     content,
+		TemplateConfig: templateConfig,
+		Content:        content,
 	}, nil
 }
 
-// Info holds detailed information about a template
+// Info holds detailed information about a template: its metadata and source
 type Info struct {
-	Name        string
-	Description string
-	MainFile    string
-	SubDir      string
-	Content     string
-}
-
-// Helper function to get template descriptions
-func getTemplateDescription(templateName string) string {
-	descriptions := map[string]string{
-		"generic": "Universal template that can be adapted to any AI tool",
-		"claude":  "Optimized for Claude Code with @context/ references",
-		"cursor":  "Designed for Cursor IDE with folder icons",
-		"copilot": "GitHub Copilot compatible with .github placement",
-		"gemini":  "Optimized for Google Gemini conversational context loading",
-	}
-
-	if desc, exists := descriptions[templateName]; exists {
-		return desc
-	}
-	return "No description available"
+	config.TemplateConfig
+	Content string
 }
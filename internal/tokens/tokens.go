@@ -0,0 +1,49 @@
+// Package tokens names the token counters and shortens text to a budget. It
+// has no dependencies so that template can share them without importing
+// classifier, which counts tokens and writes summaries.
+package tokens
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer names
+const (
+	Heuristic = "heuristic" // Estimates tokens from the length of the text
+	Default   = Heuristic
+
+	// Ellipsis marks text cut by TruncateRunes
+	Ellipsis = "..."
+)
+
+// Exact reports whether a tokenizer counts tokens instead of estimating them
+func Exact(name string) bool {
+	return name != "" && name != Heuristic
+}
+
+// TruncateRunes shortens text to at most maxLength runes, cutting at a word
+// boundary when possible and marking the cut with an ellipsis
+func TruncateRunes(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+
+	limit := maxLength - utf8.RuneCountInString(Ellipsis)
+	if limit < 1 {
+		return string(runes[:maxLength])
+	}
+
+	cut := limit
+	for i := limit; i > limit/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + Ellipsis
+}
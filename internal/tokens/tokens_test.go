@@ -0,0 +1,36 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		text string
+		want string
+	}{
+		{"short", 10, "hello", "hello"},
+		{"exact", 5, "hello", "hello"},
+		{"cut at a word", 8, "hello world", "hello..."},
+		{"trailing space", 9, "hello world", "hello..."},
+		{"tiny", 2, "hello", "he"},
+		{"multibyte", 4, "héllo", "h..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncateRunes(tt.text, tt.max); got != tt.want {
+				t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+			}
+		})
+	}
+
+	got := TruncateRunes(strings.Repeat("日本語のテキスト ", 20), 30)
+	if !utf8.ValidString(got) || utf8.RuneCountInString(got) > 30 || !strings.HasSuffix(got, Ellipsis) {
+		t.Errorf("TruncateRunes() = %q, want valid UTF-8 of at most 30 runes ending in %s", got, Ellipsis)
+	}
+}
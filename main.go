@@ -1,7 +1,13 @@
 package main
 
-import "github.com/angelcodes95/contindex/cmd"
+import (
+	"os"
+
+	"github.com/angelcodes95/contindex/cmd"
+)
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }